@@ -0,0 +1,158 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrAuthFailed matches, via errors.Is, errors from connecting with wrong credentials, or without credentials to a
+// server that requires them. The Error from Redis, e.g. WRONGPASS, is still available with errors.As.
+var ErrAuthFailed = errors.New("redis: authentication failed")
+
+type authError struct {
+	err error
+}
+
+func (e authError) Error() string {
+	return ErrAuthFailed.Error() + ": " + e.err.Error()
+}
+
+func (e authError) Unwrap() error {
+	return e.err
+}
+
+func (e authError) Is(target error) bool {
+	return target == ErrAuthFailed
+}
+
+// WithAuth authenticates every new connection with AUTH. An empty username uses the legacy password only form,
+// which logs in as the default user. Since New connects straight away when credentials are set, wrong credentials
+// make New fail with ErrAuthFailed rather than the first command. A connection that is later logged out, say by an
+// ACL change, is logged back in when a command gets NOAUTH, and that command is retried once.
+func WithAuth(username, password string) Option {
+	return func(c *Client) {
+		if username == "" {
+			c.auth = []string{"AUTH", password}
+		} else {
+			c.auth = []string{"AUTH", username, password}
+		}
+	}
+}
+
+// NewFromURL creates a Client from a URL of the form redis://[[username]:password@]host[:port][/db],
+// with the port defaulting to 6379. opts are applied after those from the URL.
+func NewFromURL(ctx context.Context, rawURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("redis: unsupported URL scheme %q", u.Scheme)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address += ":6379"
+	}
+	var urlOpts []Option
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if _, err := strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("redis: invalid database %q in URL", db)
+		}
+		urlOpts = append(urlOpts, func(c *Client) {
+			c.onConnect = append(c.onConnect, []string{"SELECT", db})
+		})
+	}
+	if password, ok := u.User.Password(); ok {
+		urlOpts = append(urlOpts, WithAuth(u.User.Username(), password))
+	}
+	return New(ctx, address, append(urlOpts, opts...)...)
+}
+
+// authFailure reports whether err, in reply to one of the setup commands, means the connection isn't authenticated.
+func authFailure(args []string, err error) bool {
+	var redisErr Error
+	if !errors.As(err, &redisErr) {
+		return false
+	}
+	return args[0] == "AUTH" || redisErr.Code() == "NOAUTH" || redisErr.Code() == "WRONGPASS"
+}
+
+// lostAuth reports whether err is NOAUTH in reply to a single command, from a Client with credentials to log back in.
+// Pipelines aren't retried, as their read may have stopped at the error with replies left unread.
+func (c *Client) lostAuth(cmds [][]string, err error) bool {
+	var redisErr Error
+	return c.auth != nil && len(cmds) == 1 && errors.As(err, &redisErr) && redisErr.Code() == "NOAUTH"
+}
+
+// reauth sends AUTH again on a connection that has been logged out.
+func (c *Client) reauth(conn net.Conn, reader *bufio.Reader) error {
+	if err := c.stats.write(conn, command(c.auth...)); err != nil {
+		return err
+	}
+	if err := expectOK(reader); err != nil {
+		if isRedisError(err) {
+			return authError{err: err}
+		}
+		return err
+	}
+	return nil
+}
+
+// ACLWhoAmI returns the name of the user the connection is authenticated as, "default" without WithAuth.
+func (c *Client) ACLWhoAmI(ctx context.Context) (string, error) {
+	name, _, err := c.execBulkString(ctx, "ACL", "WHOAMI")
+	return name, err
+}
+
+// ACLGetUser returns the rules of user from ACL GETUSER, keyed by name, such as "flags", "commands" and "keys".
+// Values are decoded as by Do, so "flags" is a []interface{} of strings and "commands" a string like "+@all -debug",
+// except that each of the "selectors" is a map[string]interface{} of its own. It returns nil if there's no such user.
+// Use it to find out why commands fail with NOPERM.
+func (c *Client) ACLGetUser(ctx context.Context, username string) (map[string]interface{}, error) {
+	var user map[string]interface{}
+	err := c.exec(ctx, []string{"ACL", "GETUSER", username}, func(reader *bufio.Reader) error {
+		reply, err := readReply(reader)
+		if err != nil || reply == nil {
+			return err
+		}
+		if user, err = aclRulesMap(reply); err != nil {
+			return err
+		}
+		selectors, ok := user["selectors"].([]interface{})
+		if !ok {
+			return nil
+		}
+		maps := make([]interface{}, len(selectors))
+		for i, selector := range selectors {
+			if maps[i], err = aclRulesMap(selector); err != nil {
+				return err
+			}
+		}
+		user["selectors"] = maps
+		return nil
+	})
+	return user, err
+}
+
+// aclRulesMap turns a flat array of alternating names and values into a map.
+func aclRulesMap(reply interface{}) (map[string]interface{}, error) {
+	fields, ok := reply.([]interface{})
+	if !ok || len(fields)%2 != 0 {
+		return nil, fmt.Errorf("redis: expected ACL rule name value pairs but got: %v", reply)
+	}
+	rules := make(map[string]interface{}, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		name, ok := fields[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("redis: expected an ACL rule name but got: %v", fields[i])
+		}
+		rules[name] = fields[i+1]
+	}
+	return rules, nil
+}
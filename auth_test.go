@@ -0,0 +1,235 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// listen starts a server on loopback that answers the first read of every connection with response.
+func listen(t *testing.T, response []byte) (address string, requests <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	reqs := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 4096)
+				n, err := conn.Read(buf)
+				if err != nil {
+					return
+				}
+				reqs <- string(buf[:n])
+				_, _ = conn.Write(response)
+			}()
+		}
+	}()
+	return l.Addr().String(), reqs
+}
+
+func TestWithAuth(t *testing.T) {
+	t.Parallel()
+	t.Run("Wrong credentials fail New", func(t *testing.T) {
+		t.Parallel()
+		address, _ := listen(t, asSimpleErrorString("WRONGPASS invalid username-password pair or user is disabled."))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := New(ctx, address, WithAuth("app", "wrong"))
+
+		if !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("New() error = %v, want %v", err, ErrAuthFailed)
+		}
+		var redisErr Error
+		if !errors.As(err, &redisErr) || redisErr.Code() != "WRONGPASS" {
+			t.Errorf("New() error = %v, want the WRONGPASS Error", err)
+		}
+	})
+	t.Run("Network failures are not auth failures", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := New(ctx, "-1", WithAuth("", "secret"))
+
+		if err == nil || errors.Is(err, ErrAuthFailed) {
+			t.Errorf("New() error = %v, want a dial error", err)
+		}
+	})
+	t.Run("Sends AUTH on connect", func(t *testing.T) {
+		t.Parallel()
+		address, requests := listen(t, okString)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		client, err := New(ctx, address, WithAuth("", "secret"))
+
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer client.Close()
+		if want := string(command("AUTH", "secret")); <-requests != want {
+			t.Errorf("New() should have sent %q", want)
+		}
+	})
+	t.Run("NOAUTH from setup commands is an auth failure", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithNoTouch())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = client.setup(fakeConn(t, asSimpleErrorString("NOAUTH Authentication required.")))
+
+		if !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("setup() error = %v, want %v", err, ErrAuthFailed)
+		}
+	})
+}
+
+func TestNewFromURL(t *testing.T) {
+	t.Parallel()
+	t.Run("Uses the credentials and database", func(t *testing.T) {
+		t.Parallel()
+		address, requests := listen(t, append(append([]byte{}, okString...), okString...))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		client, err := NewFromURL(ctx, "redis://app:secret@"+address+"/2")
+
+		if err != nil {
+			t.Fatalf("NewFromURL() error = %v", err)
+		}
+		defer client.Close()
+		want := string(command("AUTH", "app", "secret")) + string(command("SELECT", "2"))
+		if got := <-requests; got != want {
+			t.Errorf("NewFromURL() sent %q, want %q", got, want)
+		}
+	})
+	t.Run("Rejects other schemes", func(t *testing.T) {
+		t.Parallel()
+		if _, err := NewFromURL(context.Background(), "http://localhost"); err == nil {
+			t.Errorf("NewFromURL() should have failed")
+		}
+	})
+}
+
+func TestClient_Reauth(t *testing.T) {
+	t.Parallel()
+	t.Run("NOAUTH logs the connection back in and retries once", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.auth = []string{"AUTH", "secret"}
+		conn, requests := recordingConn(t, asSimpleErrorString("NOAUTH Authentication required."), okString, asBulkString("bar"))
+		client.pool <- conn
+
+		got, _, err := client.Get(context.Background(), "Foo")
+
+		if err != nil || got != "bar" {
+			t.Errorf("Get() got = %v, error = %v", got, err)
+		}
+		for _, want := range []string{string(command("GET", "Foo")), string(command("AUTH", "secret")), string(command("GET", "Foo"))} {
+			if got := <-requests; got != want {
+				t.Errorf("Get() sent = %q, want %q", got, want)
+			}
+		}
+	})
+	t.Run("Without credentials NOAUTH is returned", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asSimpleErrorString("NOAUTH Authentication required."))
+
+		_, _, err = client.Get(context.Background(), "Foo")
+
+		var redisErr Error
+		if !errors.As(err, &redisErr) || redisErr.Code() != "NOAUTH" {
+			t.Errorf("Get() error = %v, want NOAUTH", err)
+		}
+	})
+	t.Run("Failing to log back in is an auth failure", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.auth = []string{"AUTH", "app", "secret"}
+		client.pool <- fakeConn(t,
+			asSimpleErrorString("NOAUTH Authentication required."),
+			asSimpleErrorString("WRONGPASS invalid username-password pair or user is disabled."),
+		)
+
+		_, _, err = client.Get(context.Background(), "Foo")
+
+		if !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("Get() error = %v, want %v", err, ErrAuthFailed)
+		}
+	})
+}
+
+func TestACLCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "ACLWhoAmI",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ACLWhoAmI(ctx)
+			},
+			response:    asBulkString("app"),
+			wantCommand: []string{"ACL", "WHOAMI"},
+			want:        "app",
+		},
+		{
+			name: "ACLGetUser",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ACLGetUser(ctx, "app")
+			},
+			response: asArray(
+				asBulkString("flags"), asArray(asBulkString("on"), asBulkString("sanitize-payload")),
+				asBulkString("passwords"), asArray(),
+				asBulkString("commands"), asBulkString("+@read -debug"),
+				asBulkString("keys"), asBulkString("~cache:*"),
+				asBulkString("channels"), asBulkString(""),
+				asBulkString("selectors"), asArray(
+					asArray(asBulkString("commands"), asBulkString("+set"), asBulkString("keys"), asBulkString("~tmp:*")),
+				),
+			),
+			wantCommand: []string{"ACL", "GETUSER", "app"},
+			want: map[string]interface{}{
+				"flags":     []interface{}{"on", "sanitize-payload"},
+				"passwords": []interface{}{},
+				"commands":  "+@read -debug",
+				"keys":      "~cache:*",
+				"channels":  "",
+				"selectors": []interface{}{
+					map[string]interface{}{"commands": "+set", "keys": "~tmp:*"},
+				},
+			},
+		},
+		{
+			name: "ACLGetUser of a missing user",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ACLGetUser(ctx, "nobody")
+			},
+			response:    []byte("*-1\r\n"),
+			wantCommand: []string{"ACL", "GETUSER", "nobody"},
+			want:        map[string]interface{}(nil),
+		},
+	})
+}
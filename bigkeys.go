@@ -0,0 +1,164 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// KeyInfo describes a key, as returned by KeyInfo. BigKeys only fills in Key, Type and Size.
+type KeyInfo struct {
+	Key  string
+	Type string
+	// Size is the approximate number of bytes the key and its value take up in memory, as reported by MEMORY USAGE.
+	Size int64
+	// TTL is the remaining time to live, or zero if the key doesn't expire.
+	TTL time.Duration
+	// Encoding is the internal representation, as reported by OBJECT ENCODING.
+	Encoding string
+	// Length is the number of bytes of a string, entries of a stream or elements of any other type. It is zero for
+	// types without a length command, such as a module's.
+	Length int64
+}
+
+// KeyInfo describes key: its type, time to live, encoding, size and length, sending TYPE, PTTL, OBJECT ENCODING and
+// MEMORY USAGE together on a single connection, then the length command for its type. It returns ErrKeyNotFound if
+// key doesn't exist.
+func (c *Client) KeyInfo(ctx context.Context, key string) (KeyInfo, error) {
+	info := KeyInfo{Key: key}
+	cmds := [][]string{{"TYPE", key}, {"PTTL", key}, {"OBJECT", "ENCODING", key}, {"MEMORY", "USAGE", key}}
+	err := c.execPipeline(ctx, cmds, func(reader *bufio.Reader) error {
+		var replies [4]interface{}
+		var errs [4]error
+		for i := range replies {
+			replies[i], errs[i] = readReply(reader)
+			if errs[i] != nil && !isRedisError(errs[i]) {
+				return errs[i]
+			}
+		}
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+		info.Type, _ = replies[0].(string)
+		if ttl, ok := replies[1].(int64); ok && ttl > 0 {
+			info.TTL = time.Duration(ttl) * time.Millisecond
+		}
+		// a nil encoding or size means the key was deleted after TYPE
+		info.Encoding, _ = replies[2].(string)
+		info.Size, _ = replies[3].(int64)
+		return nil
+	})
+	if err != nil {
+		return info, err
+	}
+	if info.Type == "" || info.Type == "none" {
+		return KeyInfo{}, ErrKeyNotFound
+	}
+	if _, ok := lengthCommands[info.Type]; ok {
+		info.Length, err = c.lengthOf(ctx, key, info.Type)
+	}
+	return info, err
+}
+
+// MemoryUsage returns the approximate number of bytes key and its value take up in memory.
+// exists is false if key doesn't exist.
+func (c *Client) MemoryUsage(ctx context.Context, key string) (size int64, exists bool, err error) {
+	err = c.exec(ctx, []string{"MEMORY", "USAGE", key}, func(reader *bufio.Reader) error {
+		var err error
+		size, exists, err = readMemoryUsage(reader)
+		return err
+	})
+	return size, exists, err
+}
+
+// readMemoryUsage reads the reply to MEMORY USAGE, an integer or nil for a missing key.
+func readMemoryUsage(reader *bufio.Reader) (int64, bool, error) {
+	reply, err := readReply(reader)
+	if err != nil {
+		return 0, false, err
+	}
+	switch v := reply.(type) {
+	case int64:
+		return v, true, nil
+	case nil:
+		return 0, false, nil
+	default:
+		return 0, false, fmt.Errorf("redis: expected a size but got: %v", reply)
+	}
+}
+
+// BigKeys scans the keys matching opts and returns the biggest key of each type, largest first, like
+// redis-cli --bigkeys but measured in bytes. It sends a TYPE and MEMORY USAGE per key, so on a large keyspace it takes
+// a while, though without blocking Redis. Keys deleted during the scan are skipped.
+func (c *Client) BigKeys(ctx context.Context, opts ScanOptions) ([]KeyInfo, error) {
+	biggest := make(map[string]KeyInfo)
+	it := c.Scan(ctx, opts)
+	for it.Next() {
+		info, exists, err := c.keyTypeAndSize(ctx, it.Key())
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		if info.Size > biggest[info.Type].Size {
+			biggest[info.Type] = info
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	infos := make([]KeyInfo, 0, len(biggest))
+	for _, info := range biggest {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Size > infos[j].Size
+	})
+	return infos, nil
+}
+
+func (c *Client) keyTypeAndSize(ctx context.Context, key string) (KeyInfo, bool, error) {
+	info := KeyInfo{Key: key}
+	var exists bool
+	cmds := [][]string{{"TYPE", key}, {"MEMORY", "USAGE", key}}
+	err := c.execPipeline(ctx, cmds, func(reader *bufio.Reader) error {
+		typ, typeErr := readSimpleStringReply(reader)
+		if typeErr != nil && !isRedisError(typeErr) {
+			return typeErr
+		}
+		var err error
+		info.Size, exists, err = readMemoryUsage(reader)
+		if typeErr != nil {
+			return typeErr
+		}
+		info.Type = typ
+		return err
+	})
+	return info, exists && info.Type != "none", err
+}
+
+// lengthCommands maps each type, as reported by TYPE, to the command returning its length: bytes for a string,
+// entries for a stream and elements for the rest.
+var lengthCommands = map[string]string{
+	"string": "STRLEN",
+	"list":   "LLEN",
+	"hash":   "HLEN",
+	"set":    "SCARD",
+	"zset":   "ZCARD",
+	"stream": "XLEN",
+}
+
+// lengthOf returns the length of the value at key, which holds a value of type typ, as reported by TYPE. A key that
+// has since been deleted has a length of 0. Other types, such as none or a module's, are an error.
+func (c *Client) lengthOf(ctx context.Context, key, typ string) (int64, error) {
+	name, ok := lengthCommands[typ]
+	if !ok {
+		return 0, fmt.Errorf("redis: can't get the length of a %v", typ)
+	}
+	return c.execInteger(ctx, name, key)
+}
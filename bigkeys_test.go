@@ -0,0 +1,169 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_MemoryUsage(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "MemoryUsage",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				size, exists, err := c.MemoryUsage(ctx, "k")
+				return []interface{}{size, exists}, err
+			},
+			response:    asInteger(56),
+			wantCommand: []string{"MEMORY", "USAGE", "k"},
+			want:        []interface{}{int64(56), true},
+		},
+		{
+			name: "MemoryUsage of a missing key",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				size, exists, err := c.MemoryUsage(ctx, "k")
+				return []interface{}{size, exists}, err
+			},
+			response:    nullString,
+			wantCommand: []string{"MEMORY", "USAGE", "k"},
+			want:        []interface{}{int64(0), false},
+		},
+	})
+}
+
+func TestClient_lengthOf(t *testing.T) {
+	t.Parallel()
+	var tests []commandTest
+	for typ, name := range map[string]string{
+		"string": "STRLEN", "list": "LLEN", "hash": "HLEN", "set": "SCARD", "zset": "ZCARD", "stream": "XLEN",
+	} {
+		typ := typ
+		tests = append(tests, commandTest{
+			name: typ,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.lengthOf(ctx, "k", typ)
+			},
+			response:    asInteger(7),
+			wantCommand: []string{name, "k"},
+			want:        int64(7),
+		})
+	}
+	runCommandTests(t, tests)
+	t.Run("Unknown types are an error", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.lengthOf(context.Background(), "k", "none"); err == nil {
+			t.Errorf("lengthOf() of a missing key should fail")
+		}
+	})
+}
+
+func TestClient_BigKeys(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeAndSize := func(typ string, size int64) []byte {
+		return append(asSimpleString(typ), asInteger(size)...)
+	}
+	client.pool <- fakeConn(t,
+		asArray(asBulkString("0"), asArray(asBulkString("s1"), asBulkString("s2"), asBulkString("l1"), asBulkString("gone"))),
+		typeAndSize("string", 50),
+		typeAndSize("string", 90),
+		typeAndSize("list", 400),
+		append(asSimpleString("none"), nullString...),
+	)
+
+	got, err := client.BigKeys(context.Background(), ScanOptions{})
+
+	if err != nil {
+		t.Errorf("BigKeys() error = %v", err)
+	}
+	want := []KeyInfo{
+		{Key: "l1", Type: "list", Size: 400},
+		{Key: "s2", Type: "string", Size: 90},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BigKeys() got = %+v, want %+v", got, want)
+	}
+}
+
+func TestClient_KeyInfo(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		responses [][]byte
+		want      KeyInfo
+		wantErr   error
+	}{
+		{
+			"Key with a ttl",
+			[][]byte{
+				append(append(append(asSimpleString("hash"), asInteger(90500)...), asBulkString("listpack")...), asInteger(128)...),
+				asInteger(3),
+			},
+			KeyInfo{Key: "k", Type: "hash", Size: 128, TTL: 90500 * time.Millisecond, Encoding: "listpack", Length: 3},
+			nil,
+		},
+		{
+			"Key without a ttl",
+			[][]byte{
+				append(append(append(asSimpleString("string"), asInteger(-1)...), asBulkString("embstr")...), asInteger(56)...),
+				asInteger(5),
+			},
+			KeyInfo{Key: "k", Type: "string", Size: 56, Encoding: "embstr", Length: 5},
+			nil,
+		},
+		{
+			// there is no command to get the length of a module's type
+			"Module type",
+			[][]byte{
+				append(append(append(asSimpleString("ReJSON-RL"), asInteger(-1)...), asBulkString("raw")...), asInteger(80)...),
+			},
+			KeyInfo{Key: "k", Type: "ReJSON-RL", Size: 80, Encoding: "raw"},
+			nil,
+		},
+		{
+			"Missing key",
+			[][]byte{
+				append(append(append(asSimpleString("none"), asInteger(-2)...), nullString...), nullString...),
+			},
+			KeyInfo{},
+			ErrKeyNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := New(context.Background(), "-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn, requests := recordingConn(t, tt.responses...)
+			client.pool <- conn
+
+			got, err := client.KeyInfo(context.Background(), "k")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("KeyInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KeyInfo() got = %+v, want %+v", got, tt.want)
+			}
+			if len(tt.responses) > 1 {
+				<-requests
+				if want := string(command(lengthCommands[tt.want.Type], "k")); <-requests != want {
+					t.Errorf("KeyInfo() should have sent %q", want)
+				}
+			}
+		})
+	}
+}
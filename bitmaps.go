@@ -0,0 +1,113 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// BitPosOptions narrows the search of BitPos.
+type BitPosOptions struct {
+	// Range limits the search to Start through End, inclusive. Negative values count from the end of the string.
+	Range      bool
+	Start, End int64
+	// Bit interprets Start and End as bit offsets rather than byte offsets. Requires Redis 7.0.
+	Bit bool
+}
+
+// BitPos returns the position of the first bit set to bit, 0 or 1, in the string at key, or -1 if there is none.
+func (c *Client) BitPos(ctx context.Context, key string, bit int, opts BitPosOptions) (int64, error) {
+	result, cmd := bitPosCmd(key, bit, opts)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func bitPosCmd(key string, bit int, opts BitPosOptions) (*IntResult, pendingCmd) {
+	args := []string{"BITPOS", key, strconv.Itoa(bit)}
+	if opts.Range {
+		args = append(args, strconv.FormatInt(opts.Start, 10), strconv.FormatInt(opts.End, 10))
+		if opts.Bit {
+			args = append(args, "BIT")
+		}
+	}
+	return newIntCmd(args...)
+}
+
+// A BitFieldOp is a single subcommand of BitField. Construct one with BitFieldGet, BitFieldSet, BitFieldIncrBy
+// or BitFieldOverflow.
+type BitFieldOp struct {
+	args []string
+}
+
+// BitFieldGet reads the integer of type, such as "u8" or "i16", at offset. offset may be prefixed with # to count
+// in multiples of the type's width, e.g. "#2".
+func BitFieldGet(typ, offset string) BitFieldOp {
+	return BitFieldOp{args: []string{"GET", typ, offset}}
+}
+
+// BitFieldSet writes value as an integer of type at offset, replying with the previous value.
+func BitFieldSet(typ, offset string, value int64) BitFieldOp {
+	return BitFieldOp{args: []string{"SET", typ, offset, strconv.FormatInt(value, 10)}}
+}
+
+// BitFieldIncrBy adds increment to the integer of type at offset, replying with the new value.
+func BitFieldIncrBy(typ, offset string, increment int64) BitFieldOp {
+	return BitFieldOp{args: []string{"INCRBY", typ, offset, strconv.FormatInt(increment, 10)}}
+}
+
+// BitFieldOverflow sets how the following SET and INCRBY operations overflow: "WRAP", "SAT" or "FAIL".
+// It has no reply of its own.
+func BitFieldOverflow(mode string) BitFieldOp {
+	return BitFieldOp{args: []string{"OVERFLOW", mode}}
+}
+
+// BitField runs ops against the string at key and returns one integer per GET, SET and INCRBY operation, in order.
+// The integer is nil for an operation that didn't happen because of BitFieldOverflow("FAIL").
+func (c *Client) BitField(ctx context.Context, key string, ops ...BitFieldOp) ([]*int64, error) {
+	result, cmd := bitFieldCmd(key, ops)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func bitFieldCmd(key string, ops []BitFieldOp) (*IntsResult, pendingCmd) {
+	args := []string{"BITFIELD", key}
+	for _, op := range ops {
+		args = append(args, op.args...)
+	}
+	r := &IntsResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  args,
+		types: "*",
+		read: func(reader *bufio.Reader) error {
+			r.values, r.err = readIntegersReply(reader)
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// readIntegersReply reads an array reply of integers, including its type. Nil elements stay nil.
+func readIntegersReply(reader *bufio.Reader) ([]*int64, error) {
+	reply, err := readReply(reader)
+	if err != nil {
+		return nil, err
+	}
+	elems, ok := reply.([]interface{})
+	if !ok && reply != nil {
+		return nil, fmt.Errorf("redis: expected an array of integers but got: %v", reply)
+	}
+	values := make([]*int64, len(elems))
+	for i, elem := range elems {
+		switch v := elem.(type) {
+		case int64:
+			values[i] = &v
+		case nil:
+		case error:
+			return nil, v
+		default:
+			return nil, fmt.Errorf("redis: expected an integer but got: %v", elem)
+		}
+	}
+	return values, nil
+}
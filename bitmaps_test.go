@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBitmapCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "BitPos",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.BitPos(ctx, "b", 1, BitPosOptions{})
+			},
+			response:    asInteger(7),
+			wantCommand: []string{"BITPOS", "b", "1"},
+			want:        int64(7),
+		},
+		{
+			name: "BitPos with a bit range",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.BitPos(ctx, "b", 0, BitPosOptions{Range: true, Start: 2, End: -1, Bit: true})
+			},
+			response:    asInteger(-1),
+			wantCommand: []string{"BITPOS", "b", "0", "2", "-1", "BIT"},
+			want:        int64(-1),
+		},
+		{
+			name: "BitField",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.BitField(ctx, "b",
+					BitFieldGet("u8", "0"),
+					BitFieldOverflow("FAIL"),
+					BitFieldSet("i16", "#1", -5),
+					BitFieldIncrBy("u4", "100", 20),
+				)
+			},
+			// FAIL skipped the INCRBY, which must not look like the SET, whose old value was 0
+			response:    asArray(asInteger(3), asInteger(0), nullString),
+			wantCommand: []string{"BITFIELD", "b", "GET", "u8", "0", "OVERFLOW", "FAIL", "SET", "i16", "#1", "-5", "INCRBY", "u4", "100", "20"},
+			want:        []*int64{int64Ptr(3), int64Ptr(0), nil},
+		},
+	})
+}
+
+func int64Ptr(n int64) *int64 {
+	return &n
+}
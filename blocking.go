@@ -0,0 +1,113 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blockSlack is how long after its block timeout the reply of a blocking command is still waited for, to allow for
+// the round trip.
+const blockSlack = time.Second
+
+// blockTimeout returns how long cmds may block Redis for, and whether any of them is a blocking command, such as
+// BLPOP, XREAD with BLOCK or WAIT. A timeout of zero means forever. Commands whose timeout can't be parsed are
+// treated as not blocking, as Redis will reject them straight away.
+func blockTimeout(cmds [][]string) (timeout time.Duration, blocking bool) {
+	for _, args := range cmds {
+		d, ok := commandBlockTimeout(args)
+		if !ok {
+			continue
+		}
+		if d == 0 || (blocking && timeout == 0) {
+			timeout = 0
+		} else {
+			// commands sent together block one after another
+			timeout += d
+		}
+		blocking = true
+	}
+	return timeout, blocking
+}
+
+func commandBlockTimeout(args []string) (time.Duration, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch strings.ToUpper(args[0]) {
+	case "BLPOP", "BRPOP", "BRPOPLPUSH", "BLMOVE", "BZPOPMIN", "BZPOPMAX":
+		return parseSeconds(args, len(args)-1)
+	case "BLMPOP", "BZMPOP":
+		return parseSeconds(args, 1)
+	case "WAIT":
+		return parseMillis(args, 2)
+	case "WAITAOF":
+		return parseMillis(args, 3)
+	case "XREAD", "XREADGROUP":
+		for i := 1; i < len(args) && !strings.EqualFold(args[i], "STREAMS"); i++ {
+			if strings.EqualFold(args[i], "BLOCK") {
+				return parseMillis(args, i+1)
+			}
+		}
+	}
+	return 0, false
+}
+
+func parseSeconds(args []string, i int) (time.Duration, bool) {
+	if i < 1 || i >= len(args) {
+		return 0, false
+	}
+	seconds, err := strconv.ParseFloat(args[i], 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+func parseMillis(args []string, i int) (time.Duration, bool) {
+	if i >= len(args) {
+		return 0, false
+	}
+	millis, err := strconv.ParseInt(args[i], 10, 64)
+	if err != nil || millis < 0 {
+		return 0, false
+	}
+	return time.Duration(millis) * time.Millisecond, true
+}
+
+// blockDeadline replaces the ctx deadline getConn put on conn for a blocking command, so the connection isn't
+// abandoned while Redis is still entitled to be blocking, which would lose anything it pops afterwards.
+// The deadline is the later of the ctx deadline and the block timeout plus blockSlack, and a timeout of zero removes
+// the deadline altogether. Cancelling ctx still interrupts the command, as does its deadline when blocking forever.
+// The returned stop must be called once the reply has been read, before conn is reused.
+func blockDeadline(ctx context.Context, conn net.Conn, timeout time.Duration) (stop func(), err error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout + blockSlack)
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.After(deadline) {
+			deadline = ctxDeadline
+		}
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			if timeout == 0 || ctx.Err() == context.Canceled {
+				// interrupts the read in progress
+				_ = conn.SetDeadline(time.Now())
+			}
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}, nil
+}
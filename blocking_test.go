@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlockTimeout(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		cmds         [][]string
+		wantTimeout  time.Duration
+		wantBlocking bool
+	}{
+		{"Not blocking", [][]string{{"GET", "k"}}, 0, false},
+		{"BLPOP", [][]string{{"BLPOP", "a", "b", "1.5"}}, 1500 * time.Millisecond, true},
+		{"BZMPOP", [][]string{{"bzmpop", "2", "1", "z", "MIN"}}, 2 * time.Second, true},
+		{"WAIT", [][]string{{"WAIT", "1", "250"}}, 250 * time.Millisecond, true},
+		{"XREAD BLOCK", [][]string{{"XREAD", "COUNT", "1", "block", "100", "STREAMS", "s", "$"}}, 100 * time.Millisecond, true},
+		{"XREAD without BLOCK", [][]string{{"XREAD", "STREAMS", "BLOCK", "0"}}, 0, false},
+		{"Block forever", [][]string{{"BRPOP", "a", "0"}}, 0, true},
+		{"Pipelined timeouts add up", [][]string{{"BLPOP", "a", "1"}, {"GET", "k"}, {"BLPOP", "a", "2"}}, 3 * time.Second, true},
+		{"Forever wins in a pipeline", [][]string{{"BLPOP", "a", "1"}, {"BLPOP", "a", "0"}, {"BLPOP", "a", "2"}}, 0, true},
+		{"Unparseable timeout", [][]string{{"BLPOP", "a", "soon"}}, 0, false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			timeout, blocking := blockTimeout(tt.cmds)
+			if timeout != tt.wantTimeout || blocking != tt.wantBlocking {
+				t.Errorf("blockTimeout() got = %v, %v, want %v, %v", timeout, blocking, tt.wantTimeout, tt.wantBlocking)
+			}
+		})
+	}
+}
+
+// deadlineConn records the deadlines set on a conn.
+type deadlineConn struct {
+	net.Conn
+	mu        sync.Mutex
+	deadlines []time.Time
+}
+
+func (c *deadlineConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.deadlines = append(c.deadlines, t)
+	c.mu.Unlock()
+	return c.Conn.SetDeadline(t)
+}
+
+// slowConn replies to the first command after delay.
+func slowConn(t *testing.T, delay time.Duration, response []byte) *deadlineConn {
+	t.Helper()
+	conn, serv := net.Pipe()
+	t.Cleanup(func() { _ = serv.Close() })
+	go func() {
+		buf := make([]byte, 4096)
+		if _, err := serv.Read(buf); err != nil {
+			return
+		}
+		time.Sleep(delay)
+		_, _ = serv.Write(response)
+	}()
+	return &deadlineConn{Conn: conn}
+}
+
+func TestBlockDeadline(t *testing.T) {
+	t.Parallel()
+	popped := asArray(asBulkString("z"), asArray(asArray(asBulkString("m"), asBulkString("1"))))
+	t.Run("Blocking forever removes the deadline", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn := slowConn(t, 20*time.Millisecond, popped)
+		client.pool <- conn
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, _, ok, err := client.BZMPop(ctx, 0, []string{"z"}, true, 1)
+
+		if !ok || err != nil {
+			t.Fatalf("BZMPop() got = %v, %v", ok, err)
+		}
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		if last := conn.deadlines[len(conn.deadlines)-1]; !last.IsZero() {
+			t.Errorf("deadline during BZMPop = %v, want none", last)
+		}
+	})
+	t.Run("Blocking forever still honours ctx", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- slowConn(t, time.Minute, popped)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, _, _, err = client.BZMPop(ctx, 0, []string{"z"}, true, 1)
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("BZMPop() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if len(client.pool) != 0 {
+			t.Errorf("the interrupted connection should have been discarded")
+		}
+	})
+	t.Run("Replies are waited for past the ctx deadline until the block timeout", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- slowConn(t, 50*time.Millisecond, popped)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, _, ok, err := client.BZMPop(ctx, 100*time.Millisecond, []string{"z"}, true, 1)
+
+		if !ok || err != nil {
+			t.Errorf("BZMPop() got = %v, %v, want the member popped after the ctx deadline", ok, err)
+		}
+	})
+	t.Run("Cancelling ctx interrupts a timed block", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- slowConn(t, time.Minute, popped)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		_, _, _, err = client.BZMPop(ctx, time.Minute, []string{"z"}, true, 1)
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("BZMPop() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
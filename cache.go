@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// GetOrSet gets the value of key, or if it doesn't exist, calls fn and sets key to its result with a time to live
+// of ttl, at millisecond precision. When several goroutines miss the same key at once, only one of them calls fn,
+// and the rest wait for its result rather than all recomputing it. That only holds within this Client: other
+// processes missing the key at the same time call fn as well.
+//
+// An error from fn is returned as is and nothing is set. A waiting goroutine gives up when its ctx is done,
+// but the one calling fn carries on according to its own ctx.
+func (c *Client) GetOrSet(ctx context.Context, key string, ttl time.Duration, fn func() (string, error)) (string, error) {
+	value, exists, err := c.Get(ctx, key)
+	if err != nil || exists {
+		return value, err
+	}
+	return c.flights.do(ctx, key, func() (string, error) {
+		value, err := fn()
+		if err != nil {
+			return "", err
+		}
+		return value, c.PSetEx(ctx, key, value, ttlMillis(ttl))
+	})
+}
+
+// flightGroup collapses concurrent calls for the same key into one, in the manner of singleflight.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// do calls fn, unless a call for key is already in flight, in which case it waits for that call's result instead.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (string, error)) (string, error) {
+	g.mu.Lock()
+	if f, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.value, f.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	f := &flight{done: make(chan struct{})}
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	f.value, f.err = fn()
+	return f.value, f.err
+}
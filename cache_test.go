@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClient_GetOrSet(t *testing.T) {
+	t.Parallel()
+	t.Run("A hit doesn't call fn", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asBulkString("cached"))
+
+		got, err := client.GetOrSet(context.Background(), "Foo", time.Minute, func() (string, error) {
+			t.Errorf("fn should not be called on a hit")
+			return "", nil
+		})
+
+		if err != nil || got != "cached" {
+			t.Errorf("GetOrSet() got = %v, error = %v", got, err)
+		}
+	})
+	t.Run("A miss calls fn and sets its result", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, requests := recordingConn(t, nullString, okString)
+		client.pool <- conn
+
+		got, err := client.GetOrSet(context.Background(), "Foo", 1500*time.Millisecond, func() (string, error) {
+			return "computed", nil
+		})
+
+		if err != nil || got != "computed" {
+			t.Errorf("GetOrSet() got = %v, error = %v", got, err)
+		}
+		<-requests
+		if want := string(command("PSETEX", "Foo", "1500", "computed")); <-requests != want {
+			t.Errorf("GetOrSet() should have sent %q", want)
+		}
+	})
+	t.Run("Errors from fn are returned and nothing is set", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, requests := recordingConn(t, nullString, okString)
+		client.pool <- conn
+		errFn := errors.New("fn failed")
+
+		_, err = client.GetOrSet(context.Background(), "Foo", time.Minute, func() (string, error) {
+			return "", errFn
+		})
+
+		if !errors.Is(err, errFn) {
+			t.Errorf("GetOrSet() error = %v, want %v", err, errFn)
+		}
+		<-requests
+		if len(requests) != 0 {
+			t.Errorf("GetOrSet() should not have set anything")
+		}
+	})
+}
+
+func TestFlightGroup(t *testing.T) {
+	t.Parallel()
+	var g flightGroup
+	release := make(chan struct{})
+	leader := make(chan string)
+	go func() {
+		value, _ := g.do(context.Background(), "k", func() (string, error) {
+			<-release
+			return "v", nil
+		})
+		leader <- value
+	}()
+	for {
+		g.mu.Lock()
+		inFlight := len(g.calls)
+		g.mu.Unlock()
+		if inFlight == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// a nil fn would panic if called, rather than waiting for the call in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.do(ctx, "k", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("do() should wait for the call in flight until ctx is done, error = %v", err)
+	}
+	close(release)
+
+	if got := <-leader; got != "v" {
+		t.Errorf("leader got = %v", got)
+	}
+	if got, _ := g.do(context.Background(), "k", func() (string, error) { return "next", nil }); got != "next" {
+		t.Errorf("do() once the call is done should call fn again, got = %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting Redis while the circuit breaker is open. See WithCircuitBreaker.
+var ErrCircuitOpen = errors.New("redis: circuit breaker is open")
+
+// Defaults used by WithCircuitBreaker for zero or negative CircuitBreakerSettings fields.
+const (
+	DefaultCircuitThreshold = 5
+	DefaultCircuitCooldown  = time.Second
+)
+
+// CircuitBreakerSettings configures WithCircuitBreaker.
+type CircuitBreakerSettings struct {
+	// Threshold is the number of consecutive failures that trips the breaker. Defaults to DefaultCircuitThreshold.
+	Threshold int
+	// Cooldown is how long the breaker stays open before letting a probe command through. Defaults to DefaultCircuitCooldown.
+	Cooldown time.Duration
+}
+
+// WithCircuitBreaker fails commands fast with ErrCircuitOpen once Threshold consecutive commands have failed
+// with an i/o error, instead of making every caller wait on a Redis that is down.
+// After Cooldown a single probe command is let through. If it succeeds the breaker closes, otherwise it opens again.
+// Only i/o errors count as failures. Errors from Redis itself are a sign Redis is up, and a reply that can't be parsed
+// is a bug rather than an outage, so neither trips the breaker.
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+	if settings.Threshold <= 0 {
+		settings.Threshold = DefaultCircuitThreshold
+	}
+	if settings.Cooldown <= 0 {
+		settings.Cooldown = DefaultCircuitCooldown
+	}
+	return func(c *Client) {
+		c.breaker = &circuitBreaker{settings: settings}
+	}
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+// allow reports whether a command may be attempted, moving an open breaker to half open once it has cooled down.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < b.settings.Cooldown {
+			return false
+		}
+		// this caller is the probe, everyone else keeps failing fast until it reports back
+		b.state = circuitHalfOpen
+		return true
+	case circuitHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// the caller gave up, which says nothing about Redis, so let the next caller probe instead
+		if b.state == circuitHalfOpen {
+			b.state = circuitOpen
+		}
+	case !isServerIOError(err):
+		b.state = circuitClosed
+		b.failures = 0
+	default:
+		b.failures++
+		if b.state == circuitHalfOpen || b.failures >= b.settings.Threshold {
+			b.state = circuitOpen
+			b.openedAt = time.Now()
+		}
+	}
+}
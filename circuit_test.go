@@ -0,0 +1,148 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	t.Run("Trips after consecutive failures and closes after a successful probe", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		cooldown := 20 * time.Millisecond
+		// "-1" is not a dialable address, so every command fails with an i/o error
+		client, err := New(ctx, "-1", WithCircuitBreaker(CircuitBreakerSettings{Threshold: 2, Cooldown: cooldown}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := client.Set(ctx, "Foo", "bar"); err == nil || errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("Set() error = %v, want an i/o error", err)
+			}
+		}
+
+		err = client.Set(ctx, "Foo", "bar")
+		if !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Set() error = %v, wantErr %v", err, ErrCircuitOpen)
+		}
+
+		time.Sleep(cooldown)
+		client.pool <- fakeConn(t, okString, okString)
+		err = client.Set(ctx, "Foo", "bar")
+		if err != nil {
+			t.Errorf("Set() probe error = %v, wantErr %v", err, nil)
+		}
+		err = client.Set(ctx, "Foo", "bar")
+		if err != nil {
+			t.Errorf("Set() error = %v, wantErr %v", err, nil)
+		}
+	})
+	t.Run("Redis errors are not failures", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		client, err := New(ctx, "-1", WithCircuitBreaker(CircuitBreakerSettings{Threshold: 1, Cooldown: time.Hour}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asSimpleErrorString("ERR nope"), okString)
+		_ = client.Set(ctx, "Foo", "bar")
+
+		err = client.Set(ctx, "Foo", "bar")
+		if err != nil {
+			t.Errorf("Set() error = %v, wantErr %v", err, nil)
+		}
+	})
+	t.Run("Unparseable replies are not failures", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		client, err := New(ctx, "-1", WithCircuitBreaker(CircuitBreakerSettings{Threshold: 1, Cooldown: time.Hour}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, []byte("?\r\n"))
+		_ = client.Set(ctx, "Foo", "bar")
+
+		client.pool <- fakeConn(t, okString)
+		err = client.Set(ctx, "Foo", "bar")
+		if err != nil {
+			t.Errorf("Set() error = %v, wantErr %v", err, nil)
+		}
+	})
+	t.Run("A failed probe opens the breaker again", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		cooldown := 20 * time.Millisecond
+		client, err := New(ctx, "-1", WithCircuitBreaker(CircuitBreakerSettings{Threshold: 1, Cooldown: cooldown}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = client.Set(ctx, "Foo", "bar")
+		time.Sleep(cooldown)
+
+		err = client.Set(ctx, "Foo", "bar")
+		if err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Set() probe error = %v, want an i/o error", err)
+		}
+
+		err = client.Set(ctx, "Foo", "bar")
+		if !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Set() error = %v, wantErr %v", err, ErrCircuitOpen)
+		}
+	})
+	t.Run("Expired contexts are not failures", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithCircuitBreaker(CircuitBreakerSettings{Threshold: 1, Cooldown: time.Hour}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		for i := 0; i < 3; i++ {
+			if err := client.Set(expired, "Foo", "bar"); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Set() error = %v, wantErr %v", err, context.DeadlineExceeded)
+			}
+		}
+
+		client.pool <- fakeConn(t, okString)
+		err = client.Set(context.Background(), "Foo", "bar")
+		if err != nil {
+			t.Errorf("Set() error = %v, wantErr %v", err, nil)
+		}
+	})
+	t.Run("Commands running into their ctx are not failures", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithCircuitBreaker(CircuitBreakerSettings{Threshold: 1, Cooldown: time.Hour}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- slowConn(t, time.Minute, okString)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := client.Set(ctx, "Foo", "bar"); err == nil {
+			t.Fatalf("Set() error = %v, want a timeout", err)
+		}
+
+		client.pool <- fakeConn(t, okString)
+		err = client.Set(context.Background(), "Foo", "bar")
+		if err != nil {
+			t.Errorf("Set() error = %v, wantErr %v", err, nil)
+		}
+	})
+	t.Run("Zero settings use the defaults", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithCircuitBreaker(CircuitBreakerSettings{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := client.breaker.settings; got.Threshold != DefaultCircuitThreshold || got.Cooldown != DefaultCircuitCooldown {
+			t.Errorf("settings got = %+v, want %v and %v", got, DefaultCircuitThreshold, DefaultCircuitCooldown)
+		}
+	})
+}
@@ -0,0 +1,295 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NodeAddr is a cluster node as reported by CLUSTER SLOTS.
+type NodeAddr struct {
+	Host string
+	Port int
+	// ID is the node ID, which servers older than Redis 4.0 don't report
+	ID string
+}
+
+// String returns the address in host:port form, as accepted by New.
+func (n NodeAddr) String() string {
+	return net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+}
+
+// SlotRange is a range of hash slots, from Start to End inclusive, and the nodes serving it.
+type SlotRange struct {
+	Start, End int
+	Master     NodeAddr
+	Replicas   []NodeAddr
+}
+
+// ClusterSlots returns which nodes serve each range of hash slots, from CLUSTER SLOTS.
+// It only reads the layout, the Client still sends every command to the one node it is connected to.
+func (c *Client) ClusterSlots(ctx context.Context) ([]SlotRange, error) {
+	var ranges []SlotRange
+	err := c.exec(ctx, []string{"CLUSTER", "SLOTS"}, func(reader *bufio.Reader) error {
+		reply, err := readReply(reader)
+		if err != nil {
+			return err
+		}
+		entries, ok := reply.([]interface{})
+		if !ok && reply != nil {
+			return fmt.Errorf("redis: expected an array of slot ranges but got: %v", reply)
+		}
+		ranges = make([]SlotRange, 0, len(entries))
+		for _, entry := range entries {
+			slotRange, err := parseSlotRange(entry)
+			if err != nil {
+				return err
+			}
+			ranges = append(ranges, slotRange)
+		}
+		return nil
+	})
+	return ranges, err
+}
+
+// parseSlotRange parses one entry of CLUSTER SLOTS: the first and last slot, then the master and each replica.
+func parseSlotRange(entry interface{}) (SlotRange, error) {
+	fields, ok := entry.([]interface{})
+	if !ok || len(fields) < 3 {
+		return SlotRange{}, fmt.Errorf("redis: expected a slot range but got: %v", entry)
+	}
+	start, ok1 := fields[0].(int64)
+	end, ok2 := fields[1].(int64)
+	if !ok1 || !ok2 {
+		return SlotRange{}, fmt.Errorf("redis: expected a slot range but got: %v", entry)
+	}
+	slotRange := SlotRange{Start: int(start), End: int(end)}
+	for i, field := range fields[2:] {
+		node, err := parseNodeAddr(field)
+		if err != nil {
+			return SlotRange{}, err
+		}
+		if i == 0 {
+			slotRange.Master = node
+		} else {
+			slotRange.Replicas = append(slotRange.Replicas, node)
+		}
+	}
+	return slotRange, nil
+}
+
+// parseNodeAddr parses a node of CLUSTER SLOTS: its host, port and, from Redis 4.0, ID, ignoring any later metadata.
+func parseNodeAddr(field interface{}) (NodeAddr, error) {
+	fields, ok := field.([]interface{})
+	if !ok || len(fields) < 2 {
+		return NodeAddr{}, fmt.Errorf("redis: expected a node but got: %v", field)
+	}
+	host, ok1 := fields[0].(string)
+	port, ok2 := fields[1].(int64)
+	if !ok1 || !ok2 {
+		return NodeAddr{}, fmt.Errorf("redis: expected a node but got: %v", field)
+	}
+	node := NodeAddr{Host: host, Port: int(port)}
+	if len(fields) > 2 {
+		node.ID, _ = fields[2].(string)
+	}
+	return node, nil
+}
+
+// clusterSlots is the number of hash slots a cluster splits its keys between.
+const clusterSlots = 16384
+
+// keySlot returns the hash slot of key, the CRC16 of the key modulo 16384. If the key has a hashtag, a non-empty
+// part between the first { and the first } after it, only the hashtag is hashed, so keys sharing one share a slot.
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start != -1 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key)) % clusterSlots
+}
+
+// crc16 is the CRC-16/XMODEM checksum Redis Cluster hashes keys with.
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// MGetSmart is MGet for a cluster, where a single MGET only takes keys in the same hash slot. It groups keys by slot,
+// sends an MGET for each group to the master serving that slot, and puts the values back in the order of keys.
+// On a server with cluster support disabled, every key is sent in a plain MGet.
+//
+// The slot layout and a connection pool per master are kept on the Client, and closed with it. A MOVED reply, such as
+// after a failover or resharding, looks the layout up again and retries once.
+func (c *Client) MGetSmart(ctx context.Context, keys ...string) (values []string, exists []bool, err error) {
+	if len(keys) == 0 {
+		return c.MGet(ctx)
+	}
+	values, exists, err = c.mgetSmart(ctx, keys)
+	if isMoved(err) {
+		c.cluster.forget()
+		values, exists, err = c.mgetSmart(ctx, keys)
+	}
+	return values, exists, err
+}
+
+func (c *Client) mgetSmart(ctx context.Context, keys []string) ([]string, []bool, error) {
+	ranges, disabled, err := c.slotLayout(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if disabled {
+		return c.MGet(ctx, keys...)
+	}
+	// groups holds the indexes into keys of each slot, and slots the order the slots were first seen in
+	groups := make(map[int][]int)
+	var slots []int
+	for i, key := range keys {
+		slot := keySlot(c.keyPrefix + key)
+		if _, ok := groups[slot]; !ok {
+			slots = append(slots, slot)
+		}
+		groups[slot] = append(groups[slot], i)
+	}
+	values, exists := make([]string, len(keys)), make([]bool, len(keys))
+	for _, slot := range slots {
+		addr, ok := slotMaster(ranges, slot)
+		if !ok {
+			return nil, nil, fmt.Errorf("redis: no node serves hash slot %v", slot)
+		}
+		indexes := groups[slot]
+		groupKeys := make([]string, len(indexes))
+		for j, i := range indexes {
+			groupKeys[j] = keys[i]
+		}
+		node, err := c.cluster.node(c, addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		groupValues, groupExists, err := node.MGet(ctx, groupKeys...)
+		if err != nil {
+			return nil, nil, err
+		}
+		for j, i := range indexes {
+			values[i], exists[i] = groupValues[j], groupExists[j]
+		}
+	}
+	return values, exists, nil
+}
+
+// slotLayout returns the slot ranges of the cluster, looking them up with ClusterSlots unless they are cached.
+// disabled is set instead if the server has cluster support disabled.
+func (c *Client) slotLayout(ctx context.Context) (ranges []SlotRange, disabled bool, err error) {
+	if ranges, disabled, ok := c.cluster.layout(); ok {
+		return ranges, disabled, nil
+	}
+	ranges, err = c.ClusterSlots(ctx)
+	var redisErr Error
+	if errors.As(err, &redisErr) && strings.Contains(redisErr.msg, "cluster support disabled") {
+		c.cluster.setLayout(nil, true)
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	c.cluster.setLayout(ranges, false)
+	return ranges, false, nil
+}
+
+// isMoved reports whether err is a MOVED redirection, sent by a cluster node for a slot it no longer serves.
+func isMoved(err error) bool {
+	var redisErr Error
+	return errors.As(err, &redisErr) && redisErr.Code() == "MOVED"
+}
+
+// slotMaster returns the address of the master serving slot, if any of ranges covers it.
+func slotMaster(ranges []SlotRange, slot int) (string, bool) {
+	for _, slotRange := range ranges {
+		if slotRange.Start <= slot && slot <= slotRange.End {
+			return slotRange.Master.String(), true
+		}
+	}
+	return "", false
+}
+
+// clusterCache holds the slot layout MGetSmart routes keys by, and a Client for each master it sent to.
+// It sits behind a pointer, so views made by WithContext share it.
+type clusterCache struct {
+	mu sync.Mutex
+	// known is set once the layout has been looked up, and disabled if that found cluster support disabled
+	known    bool
+	disabled bool
+	ranges   []SlotRange
+	nodes    map[string]*Client
+	// closed is set by Client.Close, after which no more Clients are made
+	closed bool
+}
+
+func (cc *clusterCache) layout() (ranges []SlotRange, disabled, known bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.ranges, cc.disabled, cc.known
+}
+
+func (cc *clusterCache) setLayout(ranges []SlotRange, disabled bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.ranges, cc.disabled, cc.known = ranges, disabled, true
+}
+
+// forget drops the layout and the Clients of the masters, so the next MGetSmart looks them up again.
+func (cc *clusterCache) forget() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.ranges, cc.disabled, cc.known = nil, false, false
+	cc.closeNodes()
+}
+
+// node returns the Client of the master at addr, made from c the first time, or ErrClosed once c is closed.
+func (cc *clusterCache) node(c *Client, addr string) (*Client, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.closed {
+		return nil, ErrClosed
+	}
+	node, ok := cc.nodes[addr]
+	if !ok {
+		if cc.nodes == nil {
+			cc.nodes = make(map[string]*Client)
+		}
+		node = c.newNodeClient(addr)
+		cc.nodes[addr] = node
+	}
+	return node, nil
+}
+
+func (cc *clusterCache) close() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.closed = true
+	cc.closeNodes()
+}
+
+// closeNodes closes the Clients of the masters, letting commands in flight on them finish. cc.mu must be held.
+func (cc *clusterCache) closeNodes() {
+	for _, node := range cc.nodes {
+		_ = node.Close()
+	}
+	cc.nodes = nil
+}
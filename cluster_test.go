@@ -0,0 +1,285 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClusterCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "ClusterSlots",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ClusterSlots(ctx)
+			},
+			response: asArray(
+				asArray(
+					asInteger(0), asInteger(5460),
+					asArray(asBulkString("10.0.0.1"), asInteger(6379), asBulkString("a1"), asArray()),
+					asArray(asBulkString("10.0.0.4"), asInteger(6379), asBulkString("b1"), asArray()),
+				),
+				// servers older than Redis 4.0 don't report node IDs
+				asArray(asInteger(5461), asInteger(16383), asArray(asBulkString("10.0.0.2"), asInteger(6380))),
+			),
+			wantCommand: []string{"CLUSTER", "SLOTS"},
+			want: []SlotRange{
+				{
+					Start:    0,
+					End:      5460,
+					Master:   NodeAddr{Host: "10.0.0.1", Port: 6379, ID: "a1"},
+					Replicas: []NodeAddr{{Host: "10.0.0.4", Port: 6379, ID: "b1"}},
+				},
+				{Start: 5461, End: 16383, Master: NodeAddr{Host: "10.0.0.2", Port: 6380}},
+			},
+		},
+		{
+			name: "ClusterSlots outside a cluster",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ClusterSlots(ctx)
+			},
+			response:    asSimpleErrorString("ERR This instance has cluster support disabled"),
+			wantCommand: []string{"CLUSTER", "SLOTS"},
+			want:        []SlotRange(nil),
+			wantErr:     true,
+		},
+	})
+}
+
+func TestNodeAddr_String(t *testing.T) {
+	t.Parallel()
+	if got := (NodeAddr{Host: "::1", Port: 7000}).String(); got != "[::1]:7000" {
+		t.Errorf("String() got = %v", got)
+	}
+}
+
+func TestKeySlot(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"123456789", 12739},
+		{"foo", 12182},
+		{"{foo}.bar", 12182},
+		// only the first hashtag counts
+		{"x{foo}{bar}", 12182},
+		// an empty hashtag doesn't count, so the whole key is hashed
+		{"{}foo", 9500},
+		// the hashtag ends at the first }, so here it is "{bar"
+		{"foo{{bar}}", 4015},
+	}
+	for _, tt := range tests {
+		if got := keySlot(tt.key); got != tt.want {
+			t.Errorf("keySlot(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestClient_MGetSmart(t *testing.T) {
+	t.Parallel()
+	node := func(t *testing.T, address string) []byte {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, _ := strconv.Atoi(port)
+		return asArray(asBulkString(host), asInteger(int64(n)))
+	}
+	// oneMaster is the reply of CLUSTER SLOTS for a cluster of a single master at address
+	oneMaster := func(t *testing.T, address string) []byte {
+		return asArray(asArray(asInteger(0), asInteger(16383), node(t, address)))
+	}
+	t.Run("Keys are grouped by slot and sent to their masters", func(t *testing.T) {
+		t.Parallel()
+		// "a" and "{a}b" share slot 15495, "b" is in slot 3300
+		high, highRequests := listen(t, asArray(asBulkString("1"), nullString))
+		low, lowRequests := listen(t, asArray(asBulkString("2")))
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asArray(
+			asArray(asInteger(0), asInteger(8191), node(t, low)),
+			asArray(asInteger(8192), asInteger(16383), node(t, high)),
+		))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		values, exists, err := client.MGetSmart(ctx, "a", "b", "{a}b")
+
+		if err != nil {
+			t.Errorf("MGetSmart() error = %v", err)
+		}
+		if want := []string{"1", "2", ""}; !reflect.DeepEqual(values, want) {
+			t.Errorf("MGetSmart() values = %q, want %q", values, want)
+		}
+		if want := []bool{true, true, false}; !reflect.DeepEqual(exists, want) {
+			t.Errorf("MGetSmart() exists = %v, want %v", exists, want)
+		}
+		if want := string(command("MGET", "a", "{a}b")); <-highRequests != want {
+			t.Errorf("MGetSmart() should have sent %q", want)
+		}
+		if want := string(command("MGET", "b")); <-lowRequests != want {
+			t.Errorf("MGetSmart() should have sent %q", want)
+		}
+	})
+	t.Run("Keys in one slot go to their master", func(t *testing.T) {
+		t.Parallel()
+		master, requests := listen(t, asArray(asBulkString("1"), asBulkString("2")))
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, oneMaster(t, master))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		values, _, err := client.MGetSmart(ctx, "{user}:a", "{user}:b")
+
+		if err != nil || !reflect.DeepEqual(values, []string{"1", "2"}) {
+			t.Errorf("MGetSmart() got = %q, %v", values, err)
+		}
+		if want := string(command("MGET", "{user}:a", "{user}:b")); <-requests != want {
+			t.Errorf("MGetSmart() should have sent %q", want)
+		}
+	})
+	t.Run("The layout and masters are kept until Close", func(t *testing.T) {
+		t.Parallel()
+		master, requests := listenReplies(t, asArray(asBulkString("1")), asArray(asBulkString("2")))
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a second CLUSTER SLOTS would have no reply
+		client.pool <- fakeConn(t, oneMaster(t, master))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		for _, want := range []string{"1", "2"} {
+			values, _, err := client.MGetSmart(ctx, "a")
+			if err != nil || !reflect.DeepEqual(values, []string{want}) {
+				t.Fatalf("MGetSmart() got = %q, %v, want %q", values, err, want)
+			}
+		}
+		if got := strings.Count(<-requests+<-requests, "MGET"); got != 2 {
+			t.Errorf("MGetSmart() sent %v MGETs on the master's connection, want 2", got)
+		}
+		masterClient, err := client.cluster.node(client, master)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := masterClient.Get(ctx, "a"); !errors.Is(err, ErrClosed) {
+			t.Errorf("Get() on a master after Close error = %v, wantErr %v", err, ErrClosed)
+		}
+		if _, _, err := client.MGetSmart(ctx, "a"); !errors.Is(err, ErrClosed) {
+			t.Errorf("MGetSmart() after Close error = %v, wantErr %v", err, ErrClosed)
+		}
+	})
+	t.Run("MOVED looks the layout up again", func(t *testing.T) {
+		t.Parallel()
+		moved, _ := listen(t, asSimpleErrorString("MOVED 15495 127.0.0.1:1"))
+		master, requests := listen(t, asArray(asBulkString("1")))
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, oneMaster(t, moved), oneMaster(t, master))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		values, _, err := client.MGetSmart(ctx, "a")
+
+		if err != nil || !reflect.DeepEqual(values, []string{"1"}) {
+			t.Errorf("MGetSmart() got = %q, %v", values, err)
+		}
+		if want := string(command("MGET", "a")); <-requests != want {
+			t.Errorf("MGetSmart() should have sent %q to the new master", want)
+		}
+	})
+	t.Run("Outside a cluster all keys go in one MGET", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, requests := recordingConn(t,
+			asSimpleErrorString("ERR This instance has cluster support disabled"),
+			asArray(asBulkString("1"), asBulkString("2")),
+			asArray(asBulkString("3"), asBulkString("4")),
+		)
+		client.pool <- conn
+
+		values, _, err := client.MGetSmart(context.Background(), "a", "b")
+		if err != nil || !reflect.DeepEqual(values, []string{"1", "2"}) {
+			t.Errorf("MGetSmart() got = %q, %v", values, err)
+		}
+		values, _, err = client.MGetSmart(context.Background(), "a", "b")
+		if err != nil || !reflect.DeepEqual(values, []string{"3", "4"}) {
+			t.Errorf("MGetSmart() again got = %q, %v", values, err)
+		}
+
+		<-requests
+		for i := 0; i < 2; i++ {
+			if want := string(command("MGET", "a", "b")); <-requests != want {
+				t.Errorf("MGetSmart() should have sent %q, without asking for the slots again", want)
+			}
+		}
+	})
+	t.Run("Other errors looking up the slots are returned", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asSimpleErrorString("NOPERM this user has no permissions to run the 'cluster' command"))
+
+		_, _, err = client.MGetSmart(context.Background(), "a", "b")
+
+		var redisErr Error
+		if !errors.As(err, &redisErr) || redisErr.Code() != "NOPERM" {
+			t.Errorf("MGetSmart() error = %v, want the NOPERM error", err)
+		}
+	})
+}
+
+// listenReplies is like listen, but keeps each connection open, answering its requests with responses in turn.
+func listenReplies(t *testing.T, responses ...[]byte) (address string, requests <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	reqs := make(chan string, len(responses))
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 4096)
+				for _, response := range responses {
+					n, err := conn.Read(buf)
+					if err != nil {
+						return
+					}
+					reqs <- string(buf[:n])
+					_, _ = conn.Write(response)
+				}
+			}()
+		}
+	}()
+	return l.Addr().String(), reqs
+}
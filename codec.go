@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// A Codec encodes the values stored by SetObject and decodes those read by GetObject, such as JSON or msgpack.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is the default Codec, using encoding/json.
+type JSONCodec struct{}
+
+// Marshal returns the JSON encoding of v.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes the JSON in data into v.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithCodec sets the Codec used by SetObject and GetObject. It defaults to JSONCodec.
+func WithCodec(codec Codec) Option {
+	return func(c *Client) {
+		if codec != nil {
+			c.codec = codec
+		}
+	}
+}
+
+// SetObject sets key to v encoded with the Client's Codec, as a SET.
+func (c *Client) SetObject(ctx context.Context, key string, v interface{}) error {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, string(data))
+}
+
+// GetObject gets the value of key and decodes it with the Client's Codec into dest, which must be a pointer.
+// If key doesn't exist, dest is left untouched and exists is false.
+func (c *Client) GetObject(ctx context.Context, key string, dest interface{}) (exists bool, err error) {
+	value, exists, err := c.Get(ctx, key)
+	if err != nil || !exists {
+		return exists, err
+	}
+	return true, c.codec.Unmarshal([]byte(value), dest)
+}
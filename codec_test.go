@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// quotedCodec stores strings wrapped in quotes, to tell it apart from JSON
+type quotedCodec struct{}
+
+func (quotedCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte(fmt.Sprintf("%q", v)), nil
+}
+
+func (quotedCodec) Unmarshal(data []byte, v interface{}) error {
+	_, err := fmt.Sscanf(string(data), "%q", v)
+	return err
+}
+
+func TestWithCodec(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1", WithCodec(quotedCodec{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, requests := recordingConn(t, okString, asBulkString(`"bar"`))
+	client.pool <- conn
+
+	if err := client.SetObject(context.Background(), "Foo", "bar"); err != nil {
+		t.Errorf("SetObject() error = %v", err)
+	}
+	var got string
+	exists, err := client.GetObject(context.Background(), "Foo", &got)
+
+	if err != nil || !exists || got != "bar" {
+		t.Errorf("GetObject() got = %q, %v, error = %v", got, exists, err)
+	}
+	if want := string(command("SET", "Foo", `"bar"`)); <-requests != want {
+		t.Errorf("SetObject() should have sent %q", want)
+	}
+}
+
+func TestClient_GetObject(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pool <- fakeConn(t, asBulkString(`{"name":"ada","age":36}`), nullString)
+
+	var got jsonUser
+	exists, err := client.GetObject(context.Background(), "user", &got)
+	if err != nil || !exists || got != (jsonUser{Name: "ada", Age: 36}) {
+		t.Errorf("GetObject() with the default codec got = %v, %v, error = %v", got, exists, err)
+	}
+	exists, err = client.GetObject(context.Background(), "missing", &got)
+	if err != nil || exists {
+		t.Errorf("GetObject() of a missing key got = %v, error = %v", exists, err)
+	}
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+)
+
+// ConfigSet changes a server parameter at runtime with CONFIG SET. The change isn't written to redis.conf.
+// The typed helpers below cover the encoding thresholds most often tuned, and spare callers the parameter names.
+func (c *Client) ConfigSet(ctx context.Context, parameter, value string) error {
+	return c.execOK(ctx, "CONFIG", "SET", parameter, value)
+}
+
+// SetListMaxListpackSize sets list-max-listpack-size: a positive n limits each quicklist node to n entries, while
+// -1 to -5 limit it to 4, 8, 16, 32 or 64 KB. Requires Redis 7.0, older servers call it list-max-ziplist-size.
+func (c *Client) SetListMaxListpackSize(ctx context.Context, n int) error {
+	return c.ConfigSet(ctx, "list-max-listpack-size", strconv.Itoa(n))
+}
+
+// SetHashMaxListpackEntries sets hash-max-listpack-entries, the most fields a hash can hold before it is converted
+// from a listpack to a hash table. Requires Redis 7.0, older servers call it hash-max-ziplist-entries.
+func (c *Client) SetHashMaxListpackEntries(ctx context.Context, n int) error {
+	return c.ConfigSet(ctx, "hash-max-listpack-entries", strconv.Itoa(n))
+}
+
+// SetHashMaxListpackValue sets hash-max-listpack-value, the longest field or value, in bytes, a hash can hold before
+// it is converted from a listpack to a hash table. Requires Redis 7.0, older servers call it hash-max-ziplist-value.
+func (c *Client) SetHashMaxListpackValue(ctx context.Context, n int) error {
+	return c.ConfigSet(ctx, "hash-max-listpack-value", strconv.Itoa(n))
+}
+
+// SetSetMaxIntsetEntries sets set-max-intset-entries, the most members a set of integers can hold before it is
+// converted from an intset.
+func (c *Client) SetSetMaxIntsetEntries(ctx context.Context, n int) error {
+	return c.ConfigSet(ctx, "set-max-intset-entries", strconv.Itoa(n))
+}
+
+// SetSetMaxListpackEntries sets set-max-listpack-entries, the most members a set of non-integers can hold before it
+// is converted from a listpack to a hash table. Requires Redis 7.2.
+func (c *Client) SetSetMaxListpackEntries(ctx context.Context, n int) error {
+	return c.ConfigSet(ctx, "set-max-listpack-entries", strconv.Itoa(n))
+}
+
+// SetZSetMaxListpackEntries sets zset-max-listpack-entries, the most members a sorted set can hold before it is
+// converted from a listpack to a skiplist. Requires Redis 7.0, older servers call it zset-max-ziplist-entries.
+func (c *Client) SetZSetMaxListpackEntries(ctx context.Context, n int) error {
+	return c.ConfigSet(ctx, "zset-max-listpack-entries", strconv.Itoa(n))
+}
+
+// SetZSetMaxListpackValue sets zset-max-listpack-value, the longest member, in bytes, a sorted set can hold before
+// it is converted from a listpack to a skiplist. Requires Redis 7.0, older servers call it zset-max-ziplist-value.
+func (c *Client) SetZSetMaxListpackValue(ctx context.Context, n int) error {
+	return c.ConfigSet(ctx, "zset-max-listpack-value", strconv.Itoa(n))
+}
+
+// DebugQuicklistPackedThreshold sets, with DEBUG QUICKLIST-PACKED-THRESHOLD, the size in bytes above which a list
+// element is stored in a quicklist node of its own rather than in a listpack, so tests can exercise large elements
+// without allocating gigabytes. DEBUG is disabled by default since Redis 7.0, see enable-debug-command.
+func (c *Client) DebugQuicklistPackedThreshold(ctx context.Context, bytes int64) error {
+	return c.execOK(ctx, "DEBUG", "QUICKLIST-PACKED-THRESHOLD", strconv.FormatInt(bytes, 10))
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConfigCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "ConfigSet",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.ConfigSet(ctx, "maxmemory-policy", "allkeys-lru")
+			},
+			response:    okString,
+			wantCommand: []string{"CONFIG", "SET", "maxmemory-policy", "allkeys-lru"},
+		},
+		{
+			name: "SetListMaxListpackSize",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.SetListMaxListpackSize(ctx, -2)
+			},
+			response:    okString,
+			wantCommand: []string{"CONFIG", "SET", "list-max-listpack-size", "-2"},
+		},
+		{
+			name: "SetHashMaxListpackEntries",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.SetHashMaxListpackEntries(ctx, 128)
+			},
+			response:    okString,
+			wantCommand: []string{"CONFIG", "SET", "hash-max-listpack-entries", "128"},
+		},
+		{
+			name: "SetZSetMaxListpackValue",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.SetZSetMaxListpackValue(ctx, 64)
+			},
+			response:    okString,
+			wantCommand: []string{"CONFIG", "SET", "zset-max-listpack-value", "64"},
+		},
+		{
+			name: "DebugQuicklistPackedThreshold",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.DebugQuicklistPackedThreshold(ctx, 100)
+			},
+			response:    okString,
+			wantCommand: []string{"DEBUG", "QUICKLIST-PACKED-THRESHOLD", "100"},
+		},
+		{
+			name: "ConfigSet error",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.ConfigSet(ctx, "no-such-parameter", "1")
+			},
+			response:    asSimpleErrorString("ERR Unknown option or number of arguments for CONFIG SET - 'no-such-parameter'"),
+			wantCommand: []string{"CONFIG", "SET", "no-such-parameter", "1"},
+			wantErr:     true,
+		},
+	})
+}
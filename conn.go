@@ -0,0 +1,141 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Conn is a single connection checked out of a Client's pool, for sequences of commands that must share a connection,
+// such as WATCH followed by a Multi, or SELECT followed by reads. It has every method of Client, all running on the
+// one connection, and must be closed to return the connection to the pool.
+// Unlike Client, a Conn is not safe for concurrent use, and its commands are never retried or sent to a replica.
+type Conn struct {
+	*Client
+	parent *Client
+	pinned *pinnedConn
+}
+
+// pinnedConn is the connection behind a Conn, and why it was dropped if it can no longer be used.
+type pinnedConn struct {
+	conn net.Conn
+	err  error
+	// selected is set once Select has switched the connection away from the Client's database
+	selected bool
+	// watching is set once WATCH has been sent, which holds until the connection's next EXEC, DISCARD or UNWATCH
+	watching bool
+}
+
+// Conn checks out a connection from the pool, waiting for one according to ctx like any command.
+func (c *Client) Conn(ctx context.Context) (*Conn, error) {
+	if c.pinned != nil {
+		return nil, errors.New("redis: Conn can't be called on a Conn")
+	}
+	if err := c.lifecycle.begin(); err != nil {
+		return nil, err
+	}
+	conn, err := c.getConn(ctx)
+	if err != nil {
+		c.lifecycle.end()
+		return nil, err
+	}
+	pinned := &pinnedConn{conn: conn}
+	view := *c
+	view.pinned = pinned
+	view.replicas = nil
+	view.maxRetries = 0
+	return &Conn{Client: &view, parent: c, pinned: pinned}, nil
+}
+
+// Session runs fn on a connection checked out with Conn, and returns it to the pool afterwards, even if fn fails.
+func (c *Client) Session(ctx context.Context, fn func(conn *Conn) error) error {
+	conn, err := c.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return fn(conn)
+}
+
+// Select switches the connection to database db for the commands that follow on this Conn.
+// It is the way to reach another database for a few commands, without a second Client. Don't send SELECT
+// through a Client with Do: the pooled connection it lands on goes back to the pool, and later commands
+// picking it up run against the wrong database.
+// A Conn that has used Select is closed instead of returned to the pool by Close.
+func (c *Conn) Select(ctx context.Context, db int) error {
+	err := c.execOK(ctx, "SELECT", strconv.Itoa(db))
+	if err == nil {
+		c.pinned.selected = true
+	}
+	return err
+}
+
+// Close returns the connection to the pool, unless an error left it unusable, or Select changed its database.
+// Keys still watched are unwatched first, so they can't abort a Multi that later picks up the connection.
+// Calling Close again does nothing. Any other state set on the connection goes back to the pool with it.
+func (c *Conn) Close() error {
+	if c.pinned.err == errConnReturned {
+		return nil
+	}
+	if c.pinned.conn != nil && c.pinned.watching && !c.pinned.selected {
+		if err := c.execOK(context.Background(), "UNWATCH"); err != nil && c.pinned.conn != nil {
+			_ = c.pinned.conn.Close()
+			c.pinned.conn = nil
+		}
+	}
+	conn := c.pinned.conn
+	if conn != nil && c.pinned.selected {
+		_ = conn.Close()
+		conn = nil
+	}
+	if conn != nil {
+		c.parent.putConn(conn, nil)
+	} else if c.parent.single != nil {
+		// the connection is gone, but it still holds the turn of a Client made WithoutPool
+		c.parent.putSingle(nil, nil)
+	}
+	c.pinned.conn = nil
+	c.pinned.err = errConnReturned
+	c.parent.lifecycle.end()
+	return nil
+}
+
+var errConnReturned = errors.New("redis: Conn has been closed")
+
+// get is getConn for a Conn.
+func (p *pinnedConn) get(ctx context.Context) (net.Conn, error) {
+	if p.conn == nil {
+		return nil, p.err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := p.conn.SetDeadline(deadline); err != nil {
+		p.drop(err)
+		return nil, p.err
+	}
+	return p.conn, nil
+}
+
+// watch notes whether cmds WATCH any keys, which Close has to undo before pooling the connection.
+func (p *pinnedConn) watch(cmds [][]string) {
+	for _, args := range cmds {
+		if len(args) > 0 && strings.EqualFold(args[0], "WATCH") {
+			p.watching = true
+		}
+	}
+}
+
+// put is putConn for a Conn, closing the connection under the same conditions.
+func (p *pinnedConn) put(err error) {
+	if err != nil && !isRedisError(err) {
+		p.drop(err)
+	}
+}
+
+func (p *pinnedConn) drop(err error) {
+	_ = p.conn.Close()
+	p.conn = nil
+	p.err = fmt.Errorf("redis: Conn closed after an error: %w", err)
+}
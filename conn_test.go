@@ -0,0 +1,157 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClient_Session(t *testing.T) {
+	t.Parallel()
+	t.Run("Commands share one connection, which goes back to the pool", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, written := recordingConn(t, okString, asBulkString("bar"))
+		client.pool <- conn
+		// a second idle conn, which the session must not use
+		other := fakeConn(t)
+		client.pool <- other
+
+		err = client.Session(context.Background(), func(conn *Conn) error {
+			if _, err := conn.Do(context.Background(), "SELECT", "2"); err != nil {
+				return err
+			}
+			got, _, err := conn.Get(context.Background(), "Foo")
+			if got != "bar" {
+				t.Errorf("Get() got = %v", got)
+			}
+			return err
+		})
+
+		if err != nil {
+			t.Errorf("Session() error = %v", err)
+		}
+		if got := <-written + <-written; got != string(command("SELECT", "2"))+string(command("GET", "Foo")) {
+			t.Errorf("Session() sent %q", got)
+		}
+		if len(client.pool) != 2 {
+			t.Errorf("Session() should have returned the connection, %v idle", len(client.pool))
+		}
+	})
+	t.Run("The connection is returned when fn fails", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t)
+		errFn := errors.New("fn failed")
+
+		err = client.Session(context.Background(), func(conn *Conn) error {
+			return errFn
+		})
+
+		if !errors.Is(err, errFn) {
+			t.Errorf("Session() error = %v, want %v", err, errFn)
+		}
+		if len(client.pool) != 1 {
+			t.Errorf("Session() should have returned the connection")
+		}
+	})
+	t.Run("A broken connection is discarded, not swapped for another", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- brokenConn(t)
+		client.pool <- fakeConn(t, asBulkString("bar"))
+
+		err = client.Session(context.Background(), func(conn *Conn) error {
+			if _, _, err := conn.Get(context.Background(), "Foo"); err == nil {
+				t.Errorf("Get() should fail on the broken connection")
+			}
+			_, _, err := conn.Get(context.Background(), "Foo")
+			return err
+		})
+
+		if err == nil {
+			t.Errorf("Session() should fail once its connection is gone")
+		}
+		if len(client.pool) != 1 {
+			t.Errorf("Only the other connection should be left, %v idle", len(client.pool))
+		}
+	})
+}
+
+func TestConn_Select(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, written := recordingConn(t, okString, asBulkString("bar"))
+	client.pool <- conn
+
+	err = client.Session(context.Background(), func(conn *Conn) error {
+		if err := conn.Select(context.Background(), 3); err != nil {
+			return err
+		}
+		_, _, err := conn.Get(context.Background(), "Foo")
+		return err
+	})
+
+	if err != nil {
+		t.Errorf("Session() error = %v", err)
+	}
+	if got := <-written + <-written; got != string(command("SELECT", "3"))+string(command("GET", "Foo")) {
+		t.Errorf("Session() sent %q", got)
+	}
+	if len(client.pool) != 0 {
+		t.Errorf("A connection on another database should not go back to the pool, %v idle", len(client.pool))
+	}
+}
+
+func TestConn_Close_Unwatches(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	queued := asSimpleString("QUEUED")
+	conn, written := recordingConn(t, okString, okString, append(append(okString, queued...), asArray(asInteger(1))...))
+	client.pool <- conn
+
+	err = client.Session(context.Background(), func(conn *Conn) error {
+		_, err := conn.Do(context.Background(), "WATCH", "Foo")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the pooled connection must not still be watching Foo, or a change to it would abort this unrelated Multi
+	multi := client.Multi()
+	incr := multi.Incr("Bar")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := multi.Exec(ctx); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+
+	if got, err := incr.Result(); got != 1 || err != nil {
+		t.Errorf("Incr() got = %v, %v", got, err)
+	}
+	if got := <-written; got != string(command("WATCH", "Foo")) {
+		t.Errorf("Session() sent %q", got)
+	}
+	if got := <-written; got != string(command("UNWATCH")) {
+		t.Errorf("Close() sent %q, want UNWATCH", got)
+	}
+	if got, want := <-written, string(command("MULTI"))+string(command("INCR", "Bar"))+string(command("EXEC")); got != want {
+		t.Errorf("Exec() sent %q, want %q", got, want)
+	}
+}
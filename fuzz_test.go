@@ -0,0 +1,41 @@
+//go:build go1.18
+
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+// FuzzReadReply feeds arbitrary bytes to the reply readers, which must return a value or an error, and never panic,
+// however malformed the reply.
+func FuzzReadReply(f *testing.F) {
+	for _, seed := range [][]byte{
+		okString,
+		nullString,
+		asInteger(-42),
+		asBulkString("hello"),
+		asSimpleErrorString("ERR unknown command"),
+		asArray(asBulkString("a"), asInteger(1), asArray(), []byte("*-1\r\n")),
+		[]byte("=8\r\ntxt:text\r\n"),
+		[]byte("(12345678901234567890\r\n"),
+		[]byte("$0\r\n\r\n"),
+		[]byte("$-3\r\n"),
+		[]byte("*"),
+		[]byte("\r\n"),
+		[]byte("+\n"),
+		{},
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		newReader := func() *bufio.Reader { return bufio.NewReaderSize(bytes.NewReader(data), 16) }
+		_, _ = readReply(newReader())
+		_, _ = readSimpleStringReply(newReader())
+		_, _ = readIntegerReply(newReader())
+		_, _, _ = readBulkStringReply(newReader())
+		_, _ = readStringsReply(newReader())
+		_ = expectOK(newReader())
+	})
+}
@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrCommandNotAllowed is returned for commands disabled by WithDisallowedCommands.
+var ErrCommandNotAllowed = errors.New("redis: command not allowed")
+
+// WithDisallowedCommands makes every method return ErrCommandNotAllowed, without writing anything to the server,
+// when it would send one of the named commands, e.g. FLUSHALL or CONFIG. Names are case-insensitive.
+func WithDisallowedCommands(names ...string) Option {
+	return func(c *Client) {
+		if c.disallowed == nil {
+			c.disallowed = make(map[string]bool)
+		}
+		for _, name := range names {
+			c.disallowed[strings.ToUpper(name)] = true
+		}
+	}
+}
+
+// checkAllowed returns ErrCommandNotAllowed if any of cmds is disallowed.
+func (c *Client) checkAllowed(cmds [][]string) error {
+	if len(c.disallowed) == 0 {
+		return nil
+	}
+	for _, args := range cmds {
+		if len(args) > 0 && c.disallowed[strings.ToUpper(args[0])] {
+			return ErrCommandNotAllowed
+		}
+	}
+	return nil
+}
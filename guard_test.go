@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithDisallowedCommands(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1", WithDisallowedCommands("flushall", "CONFIG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, written := recordingConn(t, okString)
+	client.pool <- conn
+
+	if _, err := client.Do(context.Background(), "FlushAll"); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Errorf("Do() error = %v, want %v", err, ErrCommandNotAllowed)
+	}
+	if _, err := client.DoArgs(context.Background(), "config", "SET", "maxmemory", 0); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Errorf("DoArgs() error = %v, want %v", err, ErrCommandNotAllowed)
+	}
+	pipeline := client.Pipeline()
+	pipeline.Incr("counter")
+	_, cmd := newStringCmd("FLUSHALL")
+	pipeline.queue(cmd)
+	if err := pipeline.Exec(context.Background()); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Errorf("Pipeline.Exec() error = %v, want %v", err, ErrCommandNotAllowed)
+	}
+
+	if err := client.Set(context.Background(), "Foo", "bar"); err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+	if got := <-written; got != string(command("SET", "Foo", "bar")) {
+		t.Errorf("Only the allowed command should be written, got %q", got)
+	}
+}
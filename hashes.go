@@ -0,0 +1,128 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// HSetNX sets field in the hash at key to value, only if field does not exist yet.
+// It reports whether the field was set.
+func (c *Client) HSetNX(ctx context.Context, key, field, value string) (bool, error) {
+	result, cmd := hSetNXCmd(key, field, value)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func hSetNXCmd(key, field, value string) (*BoolResult, pendingCmd) {
+	return newBoolCmd("HSETNX", key, field, value)
+}
+
+// HSetMulti sets every field in fields of the hash at key in a single HSET, replacing the deprecated HMSET, and
+// returns how many of them are new rather than updated. Fields are sent in sorted order, so the command is the same
+// from one call to the next.
+func (c *Client) HSetMulti(ctx context.Context, key string, fields map[string]string) (int64, error) {
+	result, cmd := hSetMultiCmd(key, fields)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func hSetMultiCmd(key string, fields map[string]string) (*IntResult, pendingCmd) {
+	if len(fields) == 0 {
+		result, cmd := newIntCmd()
+		cmd.fail(fmt.Errorf("redis: HSetMulti requires at least one field"))
+		return result, cmd
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := make([]string, 0, 2+2*len(fields))
+	args = append(args, "HSET", key)
+	for _, name := range names {
+		args = append(args, name, fields[name])
+	}
+	return newIntCmd(args...)
+}
+
+// HIncrByFloat increments the number stored in field of the hash at key by delta and returns the new value.
+// A missing key or field is treated as 0.
+func (c *Client) HIncrByFloat(ctx context.Context, key, field string, delta float64) (float64, error) {
+	result, cmd := hIncrByFloatCmd(key, field, delta)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func hIncrByFloatCmd(key, field string, delta float64) (*FloatResult, pendingCmd) {
+	return newFloatCmd("HINCRBYFLOAT", key, field, formatFloat(delta))
+}
+
+// HRandField returns up to count random fields of the hash at key.
+// A negative count returns exactly -count fields, which may include the same field more than once.
+func (c *Client) HRandField(ctx context.Context, key string, count int64) ([]string, error) {
+	result, cmd := hRandFieldCmd(key, count)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func hRandFieldCmd(key string, count int64) (*StringsResult, pendingCmd) {
+	return newStringsCmd("HRANDFIELD", key, strconv.FormatInt(count, 10))
+}
+
+// HGetAll returns every field and value of the hash at key.
+func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	result, cmd := hGetAllCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func hGetAllCmd(key string) (*MapResult, pendingCmd) {
+	return newMapCmd("HGETALL", key)
+}
+
+// HLen returns the number of fields in the hash at key, or 0 if key doesn't exist.
+func (c *Client) HLen(ctx context.Context, key string) (int64, error) {
+	result, cmd := hLenCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func hLenCmd(key string) (*IntResult, pendingCmd) {
+	return newIntCmd("HLEN", key)
+}
+
+// HKeys returns every field of the hash at key, without their values. It returns an empty slice if key doesn't exist.
+func (c *Client) HKeys(ctx context.Context, key string) ([]string, error) {
+	result, cmd := hKeysCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func hKeysCmd(key string) (*StringsResult, pendingCmd) {
+	return newStringsCmd("HKEYS", key)
+}
+
+// HVals returns every value of the hash at key, without their fields. It returns an empty slice if key doesn't exist.
+func (c *Client) HVals(ctx context.Context, key string) ([]string, error) {
+	result, cmd := hValsCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func hValsCmd(key string) (*StringsResult, pendingCmd) {
+	return newStringsCmd("HVALS", key)
+}
+
+// pairs turns a flat reply of alternating keys and values into a map.
+func pairs(flat []string) (map[string]string, error) {
+	if len(flat)%2 != 0 {
+		return nil, fmt.Errorf("redis: expected pairs but got %v elements", len(flat))
+	}
+	m := make(map[string]string, len(flat)/2)
+	for i := 0; i < len(flat); i += 2 {
+		m[flat[i]] = flat[i+1]
+	}
+	return m, nil
+}
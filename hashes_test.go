@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHashCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "HSetNX sets an absent field",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.HSetNX(ctx, "h", "f", "v")
+			},
+			response:    asInteger(1),
+			wantCommand: []string{"HSETNX", "h", "f", "v"},
+			want:        true,
+		},
+		{
+			name: "HSetNX leaves an existing field",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.HSetNX(ctx, "h", "f", "v")
+			},
+			response:    asInteger(0),
+			wantCommand: []string{"HSETNX", "h", "f", "v"},
+			want:        false,
+		},
+		{
+			name: "HSetMulti sends fields in sorted order",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.HSetMulti(ctx, "h", map[string]string{"b": "2", "c": "3", "a": "1"})
+			},
+			response:    asInteger(2),
+			wantCommand: []string{"HSET", "h", "a", "1", "b", "2", "c", "3"},
+			want:        int64(2),
+		},
+		{
+			name: "HIncrByFloat",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.HIncrByFloat(ctx, "h", "f", -0.1)
+			},
+			response:    asBulkString("10.4"),
+			wantCommand: []string{"HINCRBYFLOAT", "h", "f", "-0.1"},
+			want:        10.4,
+		},
+		{
+			name: "HRandField",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.HRandField(ctx, "h", -2)
+			},
+			response:    asArray(asBulkString("f"), asBulkString("f")),
+			wantCommand: []string{"HRANDFIELD", "h", "-2"},
+			want:        []string{"f", "f"},
+		},
+		{
+			name: "HGetAll",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.HGetAll(ctx, "h")
+			},
+			response:    asArray(asBulkString("f1"), asBulkString("v1"), asBulkString("f2"), asBulkString("v2")),
+			wantCommand: []string{"HGETALL", "h"},
+			want:        map[string]string{"f1": "v1", "f2": "v2"},
+		},
+		{
+			name: "HLen",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.HLen(ctx, "h")
+			},
+			response:    asInteger(2),
+			wantCommand: []string{"HLEN", "h"},
+			want:        int64(2),
+		},
+		{
+			name: "HKeys",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.HKeys(ctx, "h")
+			},
+			response:    asArray(asBulkString("f1"), asBulkString("f2")),
+			wantCommand: []string{"HKEYS", "h"},
+			want:        []string{"f1", "f2"},
+		},
+		{
+			name: "HVals",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.HVals(ctx, "h")
+			},
+			response:    asArray(asBulkString("v1"), asBulkString("v2")),
+			wantCommand: []string{"HVALS", "h"},
+			want:        []string{"v1", "v2"},
+		},
+		{
+			name: "HVals returns an empty slice for an empty hash",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.HVals(ctx, "h")
+			},
+			response:    asArray(),
+			wantCommand: []string{"HVALS", "h"},
+			want:        []string{},
+		},
+	})
+}
@@ -0,0 +1,122 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Hooks are called around every command a Client sends, for example to log or trace them.
+// Either func may be nil. A command that is retried is still only reported once.
+// Secrets such as the password of AUTH are replaced with "***" in the args hooks see, see WithArgRedaction.
+//
+// The ctx passed to hooks is exactly the one the command was called with, never one derived from it, so values the
+// caller stored in it, such as a trace ID or tenant, can be read from it.
+type Hooks struct {
+	// BeforeCommand is called before args is sent.
+	BeforeCommand func(ctx context.Context, args []string)
+	// AfterCommand is called once the reply to args has been read, with the error the caller will see.
+	// elapsed is how long the command took as the caller saw it, including waiting for a connection and any retries.
+	AfterCommand func(ctx context.Context, args []string, elapsed time.Duration, err error)
+}
+
+// WithHooks registers hooks on the Client. It may be passed more than once, hooks run in the order they were added.
+func WithHooks(hooks Hooks) Option {
+	return func(c *Client) {
+		c.hooks = append(c.hooks, hooks)
+	}
+}
+
+// WithArgRedaction replaces the arguments for which redact returns true with "***" in the args passed to hooks,
+// so values such as session tokens don't end up in logs. cmd is the upper cased command name, and argIndex the
+// position in args, so the first argument after the command is 1. The arguments of AUTH are always redacted.
+// The command sent to Redis is unchanged.
+func WithArgRedaction(redact func(cmd string, argIndex int) bool) Option {
+	return func(c *Client) {
+		c.redact = redact
+	}
+}
+
+const redacted = "***"
+
+// redactArgs returns cmds as hooks should see them, copying only the commands that have arguments to redact.
+func (c *Client) redactArgs(cmds [][]string) [][]string {
+	var out [][]string
+	for i, args := range cmds {
+		if len(args) == 0 {
+			continue
+		}
+		name := strings.ToUpper(args[0])
+		var copied []string
+		for j := 1; j < len(args); j++ {
+			if name != "AUTH" && (c.redact == nil || !c.redact(name, j)) {
+				continue
+			}
+			if copied == nil {
+				copied = append([]string(nil), args...)
+			}
+			copied[j] = redacted
+		}
+		if copied == nil {
+			continue
+		}
+		if out == nil {
+			out = append([][]string(nil), cmds...)
+		}
+		out[i] = copied
+	}
+	if out == nil {
+		return cmds
+	}
+	return out
+}
+
+type hooksKey struct{}
+
+// ContextWithHooks returns a copy of ctx carrying request-scoped hooks, such as a logger tagged with a request ID,
+// for Client.WithContext to pick up.
+func ContextWithHooks(ctx context.Context, hooks Hooks) context.Context {
+	existing, _ := ctx.Value(hooksKey{}).([]Hooks)
+	all := append(append([]Hooks(nil), existing...), hooks)
+	return context.WithValue(ctx, hooksKey{}, all)
+}
+
+// WithContext returns a lightweight view of c that runs the hooks attached to ctx with ContextWithHooks, in addition
+// to c's own. The view shares c's connection pool and settings, so it is cheap to create one per request,
+// and it doesn't need to be closed. Commands on the view still take their own context for cancellation and deadlines.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	scoped, _ := ctx.Value(hooksKey{}).([]Hooks)
+	view := *c
+	view.hooks = append(append([]Hooks(nil), c.hooks...), scoped...)
+	return &view
+}
+
+func (c *Client) beforeCommand(ctx context.Context, cmds [][]string) {
+	if len(c.hooks) == 0 {
+		return
+	}
+	cmds = c.redactArgs(cmds)
+	for _, hooks := range c.hooks {
+		if hooks.BeforeCommand == nil {
+			continue
+		}
+		for _, args := range cmds {
+			hooks.BeforeCommand(ctx, args)
+		}
+	}
+}
+
+func (c *Client) afterCommand(ctx context.Context, cmds [][]string, elapsed time.Duration, err error) {
+	if len(c.hooks) == 0 {
+		return
+	}
+	cmds = c.redactArgs(cmds)
+	for _, hooks := range c.hooks {
+		if hooks.AfterCommand == nil {
+			continue
+		}
+		for _, args := range cmds {
+			hooks.AfterCommand(ctx, args, elapsed, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedHook struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recordedHook) hooks(name string) Hooks {
+	return Hooks{
+		BeforeCommand: func(ctx context.Context, args []string) {
+			r.record(name + " before " + args[0])
+		},
+		AfterCommand: func(ctx context.Context, args []string, elapsed time.Duration, err error) {
+			r.record(name + " after " + args[0])
+		},
+	}
+}
+
+func (r *recordedHook) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func TestWithHooks(t *testing.T) {
+	t.Parallel()
+	var recorded recordedHook
+	client, err := New(context.Background(), "-1", WithHooks(recorded.hooks("client")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pool <- fakeConn(t, okString)
+
+	err = client.Set(context.Background(), "Foo", "bar")
+
+	if err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+	if want := []string{"client before SET", "client after SET"}; !reflect.DeepEqual(recorded.calls, want) {
+		t.Errorf("hooks got = %v, want %v", recorded.calls, want)
+	}
+}
+
+func TestClient_WithContext(t *testing.T) {
+	t.Parallel()
+	var recorded recordedHook
+	client, err := New(context.Background(), "-1", WithHooks(recorded.hooks("client")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pool <- fakeConn(t, okString, okString)
+	requestCtx := ContextWithHooks(context.Background(), recorded.hooks("request"))
+
+	view := client.WithContext(requestCtx)
+	err = view.Set(context.Background(), "Foo", "bar")
+	if err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+	err = client.Set(context.Background(), "Foo", "bar")
+	if err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+
+	want := []string{
+		"client before SET", "request before SET", "client after SET", "request after SET",
+		"client before SET", "client after SET",
+	}
+	if !reflect.DeepEqual(recorded.calls, want) {
+		t.Errorf("hooks got = %v, want %v", recorded.calls, want)
+	}
+	if len(client.pool) != 1 {
+		t.Errorf("view should share the pool, pool has %v", len(client.pool))
+	}
+}
+
+func TestWithArgRedaction(t *testing.T) {
+	t.Parallel()
+	var seen [][]string
+	hooks := Hooks{BeforeCommand: func(ctx context.Context, args []string) {
+		seen = append(seen, args)
+	}}
+	redactSetValues := func(cmd string, argIndex int) bool {
+		return cmd == "SET" && argIndex == 2
+	}
+	client, err := New(context.Background(), "-1", WithHooks(hooks), WithArgRedaction(redactSetValues))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, requests := recordingConn(t, okString, okString)
+	client.pool <- conn
+
+	if _, err := client.Do(context.Background(), "auth", "user", "secret"); err != nil {
+		t.Errorf("Do() error = %v", err)
+	}
+	if err := client.Set(context.Background(), "session", "token"); err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+
+	want := [][]string{{"auth", "***", "***"}, {"SET", "session", "***"}}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("hooks got = %v, want %v", seen, want)
+	}
+	if got := <-requests + <-requests; got != string(command("auth", "user", "secret"))+string(command("SET", "session", "token")) {
+		t.Errorf("redaction should not change the commands sent, sent %q", got)
+	}
+}
+
+func TestHooks_AfterCommandElapsed(t *testing.T) {
+	t.Parallel()
+	var got time.Duration
+	hooks := Hooks{AfterCommand: func(ctx context.Context, args []string, elapsed time.Duration, err error) {
+		got = elapsed
+	}}
+	client, err := New(context.Background(), "-1", WithHooks(hooks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pool <- fakeConn(t, okString)
+
+	if err := client.Set(context.Background(), "Foo", "bar"); err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+	if got <= 0 || got < client.LastRTT() {
+		t.Errorf("AfterCommand elapsed = %v, should cover the round trip of %v", got, client.LastRTT())
+	}
+}
+
+type traceIDKey struct{}
+
+func TestHooks_CallerContext(t *testing.T) {
+	t.Parallel()
+	var seen []context.Context
+	hooks := Hooks{
+		BeforeCommand: func(ctx context.Context, args []string) {
+			seen = append(seen, ctx)
+		},
+		AfterCommand: func(ctx context.Context, args []string, elapsed time.Duration, err error) {
+			seen = append(seen, ctx)
+		},
+	}
+	client, err := New(context.Background(), "-1", WithHooks(hooks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pool <- fakeConn(t, okString, asInteger(1))
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), traceIDKey{}, "trace-1"), time.Second)
+	defer cancel()
+
+	if err := client.Set(ctx, "Foo", "bar"); err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+	pipeline := client.Pipeline()
+	pipeline.Incr("n")
+	if err := pipeline.Exec(ctx); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+
+	if len(seen) != 4 {
+		t.Fatalf("hooks called %v times, want 4", len(seen))
+	}
+	for _, got := range seen {
+		if got != ctx {
+			t.Errorf("hooks should get the caller's ctx, not one derived from it")
+		}
+		if got.Value(traceIDKey{}) != "trace-1" {
+			t.Errorf("hooks should see the caller's context values")
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ioStats counts the bytes a Client has written to and read from Redis, and times its round trips.
+type ioStats struct {
+	written int64
+	read    int64
+	lastRTT int64
+	// localCacheHits and localCacheMisses count the Gets WithLocalCache answered itself and passed on to Redis
+	localCacheHits   int64
+	localCacheMisses int64
+}
+
+// Stats is a snapshot of the counters of a Client since it was created.
+type Stats struct {
+	// BytesWritten and BytesRead are the same as IOStats.
+	BytesWritten, BytesRead int64
+	// LocalCacheHits are the Gets the cache set up by WithLocalCache answered, and LocalCacheMisses those it had to
+	// pass on to Redis. Both stay 0 without WithLocalCache.
+	LocalCacheHits, LocalCacheMisses int64
+}
+
+// Stats returns the counters of the Client so far.
+func (c *Client) Stats() Stats {
+	return Stats{
+		BytesWritten:     atomic.LoadInt64(&c.stats.written),
+		BytesRead:        atomic.LoadInt64(&c.stats.read),
+		LocalCacheHits:   atomic.LoadInt64(&c.stats.localCacheHits),
+		LocalCacheMisses: atomic.LoadInt64(&c.stats.localCacheMisses),
+	}
+}
+
+// IOStats returns the number of bytes written to and read from Redis since the Client was created,
+// including traffic to read replicas and subscriptions.
+func (c *Client) IOStats() (written, read int64) {
+	return atomic.LoadInt64(&c.stats.written), atomic.LoadInt64(&c.stats.read)
+}
+
+// LastRTT returns how long the most recent round trip to Redis took, from writing a command to reading its reply,
+// or 0 before the first reply. Unlike the elapsed time passed to Hooks, it leaves out waiting for a connection and
+// retries, so it tracks the latency of Redis and the network alone.
+func (c *Client) LastRTT() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.stats.lastRTT))
+}
+
+func (s *ioStats) recordRTT(d time.Duration) {
+	atomic.StoreInt64(&s.lastRTT, int64(d))
+}
+
+// write writes p to w, counting the bytes written.
+func (s *ioStats) write(w io.Writer, p []byte) error {
+	n, err := w.Write(p)
+	atomic.AddInt64(&s.written, int64(n))
+	return err
+}
+
+// reader counts the bytes read from r.
+func (s *ioStats) reader(r io.Reader) io.Reader {
+	return &countingReader{r: r, n: &s.read}
+}
+
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	atomic.AddInt64(r.n, int64(n))
+	return n, err
+}
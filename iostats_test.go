@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClient_IOStats(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pool <- fakeConn(t, asBulkString("bar"))
+
+	if _, _, err := client.Get(context.Background(), "Foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	written, read := client.IOStats()
+	if want := int64(len(command("GET", "Foo"))); written != want {
+		t.Errorf("IOStats() written = %v, want %v", written, want)
+	}
+	if want := int64(len(asBulkString("bar"))); read != want {
+		t.Errorf("IOStats() read = %v, want %v", read, want)
+	}
+	if stats := client.Stats(); stats.BytesWritten != written || stats.BytesRead != read {
+		t.Errorf("Stats() = %+v, want the same bytes as IOStats()", stats)
+	}
+}
+
+func TestClient_LastRTT(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pool <- fakeConn(t, asSimpleErrorString("ERR nope"))
+
+	if client.LastRTT() != 0 {
+		t.Errorf("LastRTT() before any command = %v, want 0", client.LastRTT())
+	}
+	_, _, _ = client.Get(context.Background(), "Foo")
+
+	if client.LastRTT() <= 0 {
+		t.Errorf("LastRTT() should be set by any reply, even an error, got %v", client.LastRTT())
+	}
+}
@@ -0,0 +1,25 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// SetJSON sets key to the JSON encoding of v, as a SET.
+func (c *Client) SetJSON(ctx context.Context, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, string(data))
+}
+
+// GetJSON gets the value of key and decodes it as JSON into dest, which must be a pointer.
+// If key doesn't exist, dest is left untouched and exists is false.
+func (c *Client) GetJSON(ctx context.Context, key string, dest interface{}) (exists bool, err error) {
+	value, exists, err := c.Get(ctx, key)
+	if err != nil || !exists {
+		return exists, err
+	}
+	return true, json.Unmarshal([]byte(value), dest)
+}
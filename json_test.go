@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type jsonUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestClient_SetJSON(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, requests := recordingConn(t, okString)
+	client.pool <- conn
+
+	err = client.SetJSON(context.Background(), "user", jsonUser{Name: "ada", Age: 36})
+
+	if err != nil {
+		t.Errorf("SetJSON() error = %v", err)
+	}
+	if want := string(command("SET", "user", `{"name":"ada","age":36}`)); <-requests != want {
+		t.Errorf("SetJSON() should have sent %q", want)
+	}
+}
+
+func TestClient_GetJSON(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		response   []byte
+		want       jsonUser
+		wantExists bool
+		wantErr    bool
+	}{
+		{
+			name:       "Decodes the value",
+			response:   asBulkString(`{"name":"ada","age":36}`),
+			want:       jsonUser{Name: "ada", Age: 36},
+			wantExists: true,
+		},
+		{
+			name:     "Missing key",
+			response: nullString,
+		},
+		{
+			name:       "Invalid JSON",
+			response:   asBulkString("not json"),
+			wantExists: true,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := New(context.Background(), "-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.pool <- fakeConn(t, tt.response)
+
+			var got jsonUser
+			exists, err := client.GetJSON(context.Background(), "user", &got)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if exists != tt.wantExists || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetJSON() got = %v, %v, want %v, %v", got, exists, tt.want, tt.wantExists)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WithKeyPrefix prepends prefix to every key the Client's methods send, isolating them under a namespace in a shared
+// Redis, and strips it from the keys they return, such as from Scan. Which arguments are keys is known for the commands
+// the methods send. Do and DoArgs send their arguments verbatim, so keys passed to them need the prefix already.
+//
+// Scan only returns keys under prefix, matching its Match pattern against the rest of the key.
+func WithKeyPrefix(prefix string) Option {
+	return func(c *Client) {
+		c.keyPrefix = prefix
+	}
+}
+
+// singleKeyCommands are the commands whose only key is their first argument.
+var singleKeyCommands = map[string]bool{
+	"GET": true, "SET": true, "SETEX": true, "PSETEX": true, "SETNX": true, "GETDEL": true, "GETEX": true,
+	"GETSET": true, "GETRANGE": true, "SETRANGE": true, "STRLEN": true, "APPEND": true, "INCR": true, "INCRBY": true,
+	"INCRBYFLOAT": true, "DECR": true, "DECRBY": true, "GETBIT": true, "SETBIT": true, "BITCOUNT": true,
+	"BITPOS": true, "BITFIELD": true, "BITFIELD_RO": true,
+	"EXPIRE": true, "PEXPIRE": true, "EXPIREAT": true, "PEXPIREAT": true, "EXPIRETIME": true, "PEXPIRETIME": true,
+	"PERSIST": true, "TTL": true, "PTTL": true, "TYPE": true, "DUMP": true, "RESTORE": true,
+	"HSET": true, "HSETNX": true, "HMSET": true, "HGET": true, "HMGET": true, "HGETALL": true, "HDEL": true,
+	"HEXISTS": true, "HINCRBY": true, "HINCRBYFLOAT": true, "HKEYS": true, "HVALS": true, "HLEN": true,
+	"HSTRLEN": true, "HRANDFIELD": true, "HSCAN": true,
+	"LPUSH": true, "RPUSH": true, "LPUSHX": true, "RPUSHX": true, "LPOP": true, "RPOP": true, "LLEN": true,
+	"LRANGE": true, "LINDEX": true, "LINSERT": true, "LPOS": true, "LREM": true, "LSET": true, "LTRIM": true,
+	"SADD": true, "SREM": true, "SCARD": true, "SMEMBERS": true, "SISMEMBER": true, "SMISMEMBER": true,
+	"SPOP": true, "SRANDMEMBER": true, "SSCAN": true,
+	"ZADD": true, "ZREM": true, "ZINCRBY": true, "ZCARD": true, "ZCOUNT": true, "ZLEXCOUNT": true, "ZRANGE": true,
+	"ZRANGEBYLEX": true, "ZRANGEBYSCORE": true, "ZREVRANGE": true, "ZREVRANGEBYSCORE": true, "ZRANK": true,
+	"ZREVRANK": true, "ZSCORE": true, "ZMSCORE": true, "ZPOPMIN": true, "ZPOPMAX": true, "ZSCAN": true,
+	"XADD": true, "XLEN": true, "XRANGE": true, "XREVRANGE": true, "XTRIM": true, "XDEL": true, "PFADD": true,
+}
+
+// keyIndexes returns the indexes of the keys in args, or nil for commands without keys or that it doesn't know. For a
+// command missing arguments, some may be past the end of args.
+func keyIndexes(args []string) []int {
+	if len(args) < 2 {
+		return nil
+	}
+	name := strings.ToUpper(args[0])
+	if singleKeyCommands[name] {
+		return []int{1}
+	}
+	switch name {
+	case "DEL", "UNLINK", "EXISTS", "TOUCH", "WATCH", "MGET", "PFCOUNT", "SINTER", "SUNION", "SDIFF",
+		"SINTERSTORE", "SUNIONSTORE", "SDIFFSTORE":
+		return indexRange(1, len(args), 1)
+	case "MSET", "MSETNX":
+		return indexRange(1, len(args), 2)
+	case "BLPOP", "BRPOP", "BZPOPMIN", "BZPOPMAX":
+		// the last argument is the timeout
+		return indexRange(1, len(args)-1, 1)
+	case "RENAME", "RENAMENX", "COPY", "SMOVE", "LMOVE", "BLMOVE", "RPOPLPUSH", "BRPOPLPUSH":
+		return indexRange(1, 3, 1)
+	case "SINTERCARD", "ZINTERCARD", "LMPOP", "ZMPOP":
+		return numKeysIndexes(args, 1)
+	case "BLMPOP", "BZMPOP", "EVAL", "EVALSHA", "EVAL_RO", "EVALSHA_RO":
+		return numKeysIndexes(args, 2)
+	case "OBJECT", "MEMORY":
+		// OBJECT ENCODING key, MEMORY USAGE key and so on, but not OBJECT HELP or MEMORY STATS
+		switch strings.ToUpper(args[1]) {
+		case "ENCODING", "FREQ", "IDLETIME", "REFCOUNT", "USAGE":
+			return indexRange(2, 3, 1)
+		}
+	case "DEBUG":
+		if strings.EqualFold(args[1], "OBJECT") {
+			return indexRange(2, 3, 1)
+		}
+	case "XREAD", "XREADGROUP":
+		// the keys are the first half of what follows STREAMS, the second half being their IDs
+		for i := 1; i < len(args); i++ {
+			if strings.EqualFold(args[i], "STREAMS") {
+				rest := len(args) - i - 1
+				return indexRange(i+1, i+1+rest/2, 1)
+			}
+		}
+	}
+	return nil
+}
+
+// indexRange returns the indexes from start up to but excluding end, step apart.
+func indexRange(start, end, step int) []int {
+	var indexes []int
+	for i := start; i < end; i += step {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+// numKeysIndexes returns the indexes of the keys following the count of keys at args[at].
+func numKeysIndexes(args []string, at int) []int {
+	if at >= len(args) {
+		return nil
+	}
+	n, err := strconv.Atoi(args[at])
+	if err != nil || n < 0 || at+1+n > len(args) {
+		return nil
+	}
+	return indexRange(at+1, at+1+n, 1)
+}
+
+// prefixKeys returns cmds with the key prefix prepended to every key. cmds itself is left alone, as it may belong to
+// the caller.
+func (c *Client) prefixKeys(cmds [][]string) [][]string {
+	if c.keyPrefix == "" {
+		return cmds
+	}
+	prefixed := make([][]string, len(cmds))
+	for i, args := range cmds {
+		indexes := keyIndexes(args)
+		if len(indexes) == 0 {
+			prefixed[i] = args
+			continue
+		}
+		prefixed[i] = append([]string(nil), args...)
+		for _, j := range indexes {
+			if j < len(args) {
+				prefixed[i][j] = c.keyPrefix + args[j]
+			}
+		}
+	}
+	return prefixed
+}
+
+// stripKeyPrefix removes the key prefix from a key Redis returned.
+func (c *Client) stripKeyPrefix(key string) string {
+	return strings.TrimPrefix(key, c.keyPrefix)
+}
+
+// matchUnderPrefix returns a SCAN MATCH pattern for the keys under the key prefix that match pattern, with any glob
+// characters in the prefix escaped so they match literally.
+func (c *Client) matchUnderPrefix(pattern string) string {
+	if pattern == "" {
+		pattern = "*"
+	}
+	var b strings.Builder
+	for _, r := range c.keyPrefix {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String() + pattern
+}
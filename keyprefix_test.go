@@ -0,0 +1,208 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithKeyPrefix(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		call        func(ctx context.Context, c *Client) (interface{}, error)
+		response    []byte
+		wantCommand []string
+		want        interface{}
+	}{
+		{
+			"Single key",
+			func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.Set(ctx, "k", "v")
+			},
+			okString,
+			[]string{"SET", "app:k", "v"},
+			nil,
+		},
+		{
+			"Every key",
+			func(ctx context.Context, c *Client) (interface{}, error) {
+				values, _, err := c.MGet(ctx, "a", "b")
+				return values, err
+			},
+			asArray(asBulkString("1"), asBulkString("2")),
+			[]string{"MGET", "app:a", "app:b"},
+			[]string{"1", "2"},
+		},
+		{
+			"Two keys",
+			func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.Rename(ctx, "a", "b")
+			},
+			okString,
+			[]string{"RENAME", "app:a", "app:b"},
+			nil,
+		},
+		{
+			"Keys counted by numkeys",
+			func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SInterCard(ctx, 10, "a", "b")
+			},
+			asInteger(1),
+			[]string{"SINTERCARD", "2", "app:a", "app:b", "LIMIT", "10"},
+			int64(1),
+		},
+		{
+			"Scripts",
+			func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Unlock(ctx, "lock", "token")
+			},
+			asInteger(1),
+			[]string{"EVAL", unlockScript, "1", "app:lock", "token"},
+			true,
+		},
+		{
+			"Subcommands",
+			func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ObjectEncoding(ctx, "k")
+			},
+			asBulkString("listpack"),
+			[]string{"OBJECT", "ENCODING", "app:k"},
+			"listpack",
+		},
+		{
+			"Returned keys are stripped",
+			func(ctx context.Context, c *Client) (interface{}, error) {
+				key, _, _, err := c.ZMPop(ctx, []string{"z"}, true, 1)
+				return key, err
+			},
+			asArray(asBulkString("app:z"), asArray(asArray(asBulkString("m"), asBulkString("1")))),
+			[]string{"ZMPOP", "1", "app:z", "MIN", "COUNT", "1"},
+			"z",
+		},
+		{
+			"Do is sent verbatim",
+			func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Do(ctx, "GET", "k")
+			},
+			asBulkString("v"),
+			[]string{"GET", "k"},
+			"v",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := New(context.Background(), "-1", WithKeyPrefix("app:"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn, requests := recordingConn(t, tt.response)
+			client.pool <- conn
+
+			got, err := tt.call(context.Background(), client)
+
+			if err != nil {
+				t.Errorf("error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %#v, want %#v", got, tt.want)
+			}
+			if want := string(command(tt.wantCommand...)); <-requests != want {
+				t.Errorf("should have sent %q", want)
+			}
+		})
+	}
+}
+
+func TestWithKeyPrefix_Scan(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1", WithKeyPrefix("app[1]:"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, requests := recordingConn(t,
+		asArray(asBulkString("0"), asArray(asBulkString("app[1]:user:1"), asBulkString("app[1]:user:2"))),
+		asInteger(2),
+	)
+	client.pool <- conn
+
+	deleted, err := client.DeleteMatching(context.Background(), "user:*", 10)
+
+	if deleted != 2 || err != nil {
+		t.Errorf("DeleteMatching() got = %v, %v", deleted, err)
+	}
+	// the prefix's glob characters are escaped, and the keys are stripped then prefixed again on their way to UNLINK
+	if want := string(command("SCAN", "0", "MATCH", `app\[1\]:user:*`, "COUNT", "10")); <-requests != want {
+		t.Errorf("should have sent %q", want)
+	}
+	if want := string(command("UNLINK", "app[1]:user:1", "app[1]:user:2")); <-requests != want {
+		t.Errorf("should have sent %q", want)
+	}
+}
+
+func TestWithKeyPrefix_SetWriter(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1", WithKeyPrefix("app:"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := command("SET", "app:k", "v")
+	conn, serv := net.Pipe()
+	written := make(chan string, 1)
+	go func() {
+		defer serv.Close()
+		defer close(written)
+		buf := make([]byte, len(want))
+		if _, err := io.ReadFull(serv, buf); err != nil {
+			return
+		}
+		written <- string(buf)
+		_, _ = serv.Write(okString)
+	}()
+	client.pool <- conn
+	// without the prefix the fake server waits for bytes that never come, so don't let the test hang
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	w, err := client.SetWriter(ctx, "k", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(w, strings.NewReader("v")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	if got := <-written; got != string(want) {
+		t.Errorf("SetWriter() wrote %q, want %q", got, want)
+	}
+}
+
+func TestKeyIndexes(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		args []string
+		want []int
+	}{
+		{[]string{"get", "k"}, []int{1}},
+		{[]string{"MSET", "a", "1", "b", "2"}, []int{1, 3}},
+		{[]string{"BLPOP", "a", "b", "0"}, []int{1, 2}},
+		{[]string{"BZMPOP", "1", "2", "a", "b", "MIN"}, []int{3, 4}},
+		{[]string{"XREAD", "COUNT", "1", "STREAMS", "a", "b", "0", "0"}, []int{4, 5}},
+		{[]string{"MEMORY", "STATS"}, nil},
+		{[]string{"PING"}, nil},
+		{[]string{"ZINTERCARD", "5", "a"}, nil},
+	} {
+		if got := keyIndexes(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("keyIndexes(%q) got = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
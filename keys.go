@@ -0,0 +1,295 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrKeyNotFound is returned by commands that describe a key, and by GetScan, when that key doesn't exist.
+var ErrKeyNotFound = errors.New("redis: key not found")
+
+// ObjectInfo describes how Redis stores a key internally. See ObjectInfo.
+type ObjectInfo struct {
+	// Encoding is the internal representation, such as listpack, hashtable or embstr.
+	Encoding string
+	// RefCount is the number of references to the value.
+	RefCount int64
+	// IdleTime is how long ago the key was last read or written. It is only tracked under an LRU maxmemory-policy.
+	IdleTime time.Duration
+	// Freq is the logarithmic access frequency counter. It is only tracked under an LFU maxmemory-policy.
+	Freq int64
+}
+
+// ObjectInfo returns the encoding, refcount, idle time and access frequency of key, sending the OBJECT subcommands
+// together on a single connection. It returns ErrKeyNotFound if key doesn't exist.
+//
+// Redis only tracks one of idle time and frequency, depending on the maxmemory-policy, and rejects asking for the other.
+// That rejection is not an error here, the untracked field is simply left zero.
+func (c *Client) ObjectInfo(ctx context.Context, key string) (ObjectInfo, error) {
+	var info ObjectInfo
+	var exists bool
+	cmds := [][]string{
+		{"OBJECT", "ENCODING", key},
+		{"OBJECT", "REFCOUNT", key},
+		{"OBJECT", "IDLETIME", key},
+		{"OBJECT", "FREQ", key},
+	}
+	err := c.execPipeline(ctx, cmds, func(reader *bufio.Reader) error {
+		// a missing key is a nil reply to every subcommand, so read all of them generically before judging any
+		var replies [4]interface{}
+		var errs [4]error
+		for i := range replies {
+			replies[i], errs[i] = readReply(reader)
+			if errs[i] != nil && !isRedisError(errs[i]) {
+				return errs[i]
+			}
+		}
+		if errs[0] != nil {
+			return errs[0]
+		}
+		encoding, ok := replies[0].(string)
+		if !ok {
+			return nil
+		}
+		exists = true
+		if errs[1] != nil {
+			return errs[1]
+		}
+		info.Encoding = encoding
+		info.RefCount, _ = replies[1].(int64)
+		if idleTime, ok := replies[2].(int64); ok {
+			info.IdleTime = time.Duration(idleTime) * time.Second
+		}
+		info.Freq, _ = replies[3].(int64)
+		return nil
+	})
+	if err == nil && !exists {
+		// reported here rather than from read, so the connection isn't discarded as if the reply was bad
+		return ObjectInfo{}, ErrKeyNotFound
+	}
+	return info, err
+}
+
+// ObjectEncoding returns how Redis stores the value at key, such as "listpack", "hashtable" or "embstr", with
+// OBJECT ENCODING. It returns ErrKeyNotFound if key doesn't exist. Use ObjectInfo for the other OBJECT subcommands.
+func (c *Client) ObjectEncoding(ctx context.Context, key string) (string, error) {
+	encoding, exists, err := c.execBulkString(ctx, "OBJECT", "ENCODING", key)
+	if err == nil && !exists {
+		return "", ErrKeyNotFound
+	}
+	return encoding, err
+}
+
+// DebugObject returns the internals DEBUG OBJECT reports about key, such as serializedlength, encoding and ql_nodes,
+// keyed by name. The leading "Value at:<address>" is returned under "at". DEBUG may be disabled on the server.
+func (c *Client) DebugObject(ctx context.Context, key string) (map[string]string, error) {
+	var fields map[string]string
+	err := c.exec(ctx, []string{"DEBUG", "OBJECT", key}, func(reader *bufio.Reader) error {
+		msgType, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch msgType {
+		case '-':
+			return readErrorMessage(reader)
+		case '+':
+			line, err := readSimpleString(reader)
+			if err != nil {
+				return err
+			}
+			fields = parseDebugObject(line)
+			return nil
+		default:
+			return fmt.Errorf("redis: unexpected message type %v", msgType)
+		}
+	})
+	return fields, err
+}
+
+func parseDebugObject(line string) map[string]string {
+	fields := make(map[string]string)
+	for _, token := range strings.Fields(line) {
+		k, v, ok := cut(token, ":")
+		if !ok {
+			continue
+		}
+		fields[k] = v
+	}
+	return fields
+}
+
+// cut is strings.Cut, which isn't available in go 1.17
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// ExpireTime returns the instant key will expire, at second precision. exists is false if key doesn't exist.
+// A key that exists but has no expiry returns the zero time.Time and true. Requires Redis 7.0.
+func (c *Client) ExpireTime(ctx context.Context, key string) (expiry time.Time, exists bool, err error) {
+	result, cmd := expireTimeCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func expireTimeCmd(key string) (*ExpiryResult, pendingCmd) {
+	return newExpiryCmd(time.Second, "EXPIRETIME", key)
+}
+
+// PExpireTime is like ExpireTime, but at millisecond precision.
+func (c *Client) PExpireTime(ctx context.Context, key string) (expiry time.Time, exists bool, err error) {
+	result, cmd := pExpireTimeCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func pExpireTimeCmd(key string) (*ExpiryResult, pendingCmd) {
+	return newExpiryCmd(time.Millisecond, "PEXPIRETIME", key)
+}
+
+func newExpiryCmd(unit time.Duration, args ...string) (*ExpiryResult, pendingCmd) {
+	r := &ExpiryResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  args,
+		types: ":",
+		read: func(reader *bufio.Reader) error {
+			var n int64
+			n, r.err = readIntegerReply(reader)
+			if r.err == nil {
+				r.expiry, r.exists = expiryFromReply(n, unit)
+			}
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// expiryFromReply converts a unix timestamp reply in unit, where -1 means no expiry and -2 no key.
+func expiryFromReply(n int64, unit time.Duration) (time.Time, bool) {
+	switch {
+	case n == -2:
+		return time.Time{}, false
+	case n < 0:
+		return time.Time{}, true
+	default:
+		return time.Unix(0, n*int64(unit)), true
+	}
+}
+
+// Expire sets a time to live of ttl on key, using EXPIRE for whole seconds and PEXPIRE otherwise.
+// It reports whether the key exists, as a missing key can't be given a time to live.
+func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	result, cmd := expireCmd(key, ttl)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func expireCmd(key string, ttl time.Duration) (*BoolResult, pendingCmd) {
+	if ttl%time.Second == 0 {
+		return newBoolCmd("EXPIRE", key, strconv.FormatInt(int64(ttl/time.Second), 10))
+	}
+	return newBoolCmd("PEXPIRE", key, strconv.FormatInt(ttlMillis(ttl), 10))
+}
+
+// ttlMillis converts ttl to whole milliseconds for commands such as PEXPIRE, rounding a positive ttl under a
+// millisecond up to 1, as 0 would delete the key at once or be rejected as an invalid expire time.
+func ttlMillis(ttl time.Duration) int64 {
+	if ttl > 0 && ttl < time.Millisecond {
+		return 1
+	}
+	return ttl.Milliseconds()
+}
+
+// ExistsEach reports which of keys exist, sending an EXISTS per key together on a single connection. The map has an
+// entry for every key. With no keys, nothing is sent.
+func (c *Client) ExistsEach(ctx context.Context, keys ...string) (map[string]bool, error) {
+	exists := make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return exists, nil
+	}
+	cmds := make([][]string, len(keys))
+	for i, key := range keys {
+		cmds[i] = []string{"EXISTS", key}
+	}
+	err := c.execPipeline(ctx, cmds, func(reader *bufio.Reader) error {
+		var firstErr error
+		for _, key := range keys {
+			n, err := readIntegerReply(reader)
+			if err != nil && !isRedisError(err) {
+				return err
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			exists[key] = n > 0
+		}
+		return firstErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return exists, nil
+}
+
+// Rename renames the key src to dst, overwriting dst if it already exists. Renaming a key to itself succeeds and
+// changes nothing, but a missing src is an Error with the message "ERR no such key".
+func (c *Client) Rename(ctx context.Context, src, dst string) error {
+	result, cmd := renameCmd(src, dst)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func renameCmd(src, dst string) (*OKResult, pendingCmd) {
+	return newOKCmd("RENAME", src, dst)
+}
+
+// Type returns the type of the value stored at key: string, list, set, zset, hash or stream, or none if key doesn't exist.
+func (c *Client) Type(ctx context.Context, key string) (string, error) {
+	result, cmd := typeCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func typeCmd(key string) (*StatusResult, pendingCmd) {
+	return newStatusCmd("TYPE", key)
+}
+
+// GetAny returns the value at key together with its type, as reported by Type, dispatching to the accessor that
+// matches: Get for a string, LRange for a list, HGetAll for a hash, SMembers for a set and ZRange for a sorted set.
+// The value is a string, []string, map[string]string, []string and []string (members in score order) respectively.
+// A missing key returns nil and "none". Other types, such as streams, are an error.
+func (c *Client) GetAny(ctx context.Context, key string) (interface{}, string, error) {
+	typ, err := c.Type(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	var value interface{}
+	switch typ {
+	case "none":
+		return nil, typ, nil
+	case "string":
+		value, _, err = c.Get(ctx, key)
+	case "list":
+		value, err = c.LRange(ctx, key, 0, -1)
+	case "hash":
+		value, err = c.HGetAll(ctx, key)
+	case "set":
+		value, err = c.SMembers(ctx, key)
+	case "zset":
+		value, err = c.ZRange(ctx, key, 0, -1)
+	default:
+		return nil, typ, fmt.Errorf("redis: GetAny does not support values of type %v", typ)
+	}
+	if err != nil {
+		return nil, typ, err
+	}
+	return value, typ, nil
+}
@@ -0,0 +1,326 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_ObjectEncoding(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "ObjectEncoding",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ObjectEncoding(ctx, "h")
+			},
+			response:    asBulkString("listpack"),
+			wantCommand: []string{"OBJECT", "ENCODING", "h"},
+			want:        "listpack",
+		},
+		{
+			name: "ObjectEncoding of a missing key",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ObjectEncoding(ctx, "missing")
+			},
+			response:    nullString,
+			wantCommand: []string{"OBJECT", "ENCODING", "missing"},
+			want:        "",
+			wantErr:     true,
+		},
+	})
+}
+
+func TestClient_ObjectInfo(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		response []byte
+		want     ObjectInfo
+		wantErr  error
+	}{
+		{
+			"LRU policy",
+			append(append(append(asBulkString("listpack"), asInteger(1)...), asInteger(30)...),
+				asSimpleErrorString("ERR An LFU maxmemory policy is not selected, access frequency not tracked.")...),
+			ObjectInfo{Encoding: "listpack", RefCount: 1, IdleTime: 30 * time.Second},
+			nil,
+		},
+		{
+			"LFU policy",
+			append(append(append(asBulkString("embstr"), asInteger(1)...),
+				asSimpleErrorString("ERR An LRU maxmemory policy is selected, idle time not tracked.")...), asInteger(5)...),
+			ObjectInfo{Encoding: "embstr", RefCount: 1, Freq: 5},
+			nil,
+		},
+		{
+			"Missing key",
+			append(append(append(nullString, nullString...), nullString...), nullString...),
+			ObjectInfo{},
+			ErrKeyNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := New(context.Background(), "-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.pool <- fakeConn(t, tt.response)
+
+			got, err := client.ObjectInfo(context.Background(), "Foo")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ObjectInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ObjectInfo() got = %+v, want %+v", got, tt.want)
+			}
+			if len(client.pool) != 1 {
+				t.Errorf("Should have put the conn back, pool has %v", len(client.pool))
+			}
+		})
+	}
+}
+
+func TestClient_DebugObject(t *testing.T) {
+	t.Parallel()
+	client, responseChan := serverClientPair(t)
+	responseChan <- asSimpleString("Value at:0x7f3a refcount:1 encoding:listpack serializedlength:12 lru:123 lru_seconds_idle:4 ql_nodes:1")
+
+	got, err := client.DebugObject(context.Background(), "Foo")
+
+	if err != nil {
+		t.Errorf("DebugObject() error = %v", err)
+	}
+	want := map[string]string{
+		"at":               "0x7f3a",
+		"refcount":         "1",
+		"encoding":         "listpack",
+		"serializedlength": "12",
+		"lru":              "123",
+		"lru_seconds_idle": "4",
+		"ql_nodes":         "1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DebugObject() got = %v, want %v", got, want)
+	}
+}
+
+func TestClient_ExpireTime(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		response   []byte
+		millis     bool
+		want       time.Time
+		wantExists bool
+		wantErr    bool
+	}{
+		{"Expiring key", asInteger(1700000000), false, time.Unix(1700000000, 0), true, false},
+		{"Expiring key in milliseconds", asInteger(1700000000123), true, time.Unix(1700000000, 123e6), true, false},
+		{"No expiry", asInteger(-1), false, time.Time{}, true, false},
+		{"No key", asInteger(-2), true, time.Time{}, false, false},
+		// an error isn't mistaken for a key expiring at the unix epoch
+		{"Error", asSimpleErrorString("ERR unknown command 'EXPIRETIME'"), false, time.Time{}, false, true},
+		{"Error in milliseconds", asSimpleErrorString("ERR unknown command 'PEXPIRETIME'"), true, time.Time{}, false, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, responseChan := serverClientPair(t)
+			responseChan <- tt.response
+
+			expireTime := client.ExpireTime
+			if tt.millis {
+				expireTime = client.PExpireTime
+			}
+			got, gotExists, err := expireTime(context.Background(), "Foo")
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExpireTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ExpireTime() got = %v, want %v", got, tt.want)
+			}
+			if gotExists != tt.wantExists {
+				t.Errorf("ExpireTime() gotExists = %v, want %v", gotExists, tt.wantExists)
+			}
+		})
+	}
+}
+
+func TestKeyCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "Expire in seconds",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Expire(ctx, "k", time.Minute)
+			},
+			response:    asInteger(1),
+			wantCommand: []string{"EXPIRE", "k", "60"},
+			want:        true,
+		},
+		{
+			name: "Expire in milliseconds",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Expire(ctx, "k", 1500*time.Millisecond)
+			},
+			response:    asInteger(0),
+			wantCommand: []string{"PEXPIRE", "k", "1500"},
+			want:        false,
+		},
+		{
+			name: "Expire under a millisecond",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Expire(ctx, "k", 500*time.Microsecond)
+			},
+			response:    asInteger(1),
+			wantCommand: []string{"PEXPIRE", "k", "1"},
+			want:        true,
+		},
+		{
+			name: "Type",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Type(ctx, "k")
+			},
+			response:    asSimpleString("zset"),
+			wantCommand: []string{"TYPE", "k"},
+			want:        "zset",
+		},
+	})
+}
+
+func TestClient_ExistsEach(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, requests := recordingConn(t, append(append(asInteger(1), asInteger(0)...), asInteger(1)...))
+	client.pool <- conn
+
+	got, err := client.ExistsEach(context.Background(), "a", "b", "c")
+
+	if want := map[string]bool{"a": true, "b": false, "c": true}; !reflect.DeepEqual(got, want) || err != nil {
+		t.Errorf("ExistsEach() got = %v, %v, want %v", got, err, want)
+	}
+	want := string(command("EXISTS", "a")) + string(command("EXISTS", "b")) + string(command("EXISTS", "c"))
+	if got := <-requests; got != want {
+		t.Errorf("ExistsEach() sent %q, want %q", got, want)
+	}
+	if got, err := client.ExistsEach(context.Background()); len(got) != 0 || err != nil {
+		t.Errorf("ExistsEach() of no keys got = %v, %v", got, err)
+	}
+}
+
+func TestClient_Rename(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "Rename",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.Rename(ctx, "a", "b")
+			},
+			response:    okString,
+			wantCommand: []string{"RENAME", "a", "b"},
+		},
+		{
+			name: "Rename to itself",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.Rename(ctx, "a", "a")
+			},
+			response:    okString,
+			wantCommand: []string{"RENAME", "a", "a"},
+		},
+	})
+	t.Run("A missing source is an Error, not an i/o failure", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asSimpleErrorString("ERR no such key"), okString)
+
+		err = client.Rename(context.Background(), "missing", "b")
+
+		var redisErr Error
+		if !errors.As(err, &redisErr) || redisErr.Error() != "ERR no such key" {
+			t.Errorf("Rename() error = %#v, want the Error %q", err, "ERR no such key")
+		}
+		// the connection is still good, so it went back to the pool for the next command
+		if err := client.Rename(context.Background(), "a", "b"); err != nil {
+			t.Errorf("Rename() after a missing source error = %v", err)
+		}
+	})
+}
+
+func TestClient_GetAny(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		responses [][]byte
+		want      interface{}
+		wantType  string
+		wantErr   bool
+	}{
+		{"String", [][]byte{asSimpleString("string"), asBulkString("v")}, "v", "string", false},
+		{"List", [][]byte{asSimpleString("list"), asArray(asBulkString("a"))}, []string{"a"}, "list", false},
+		{"Hash", [][]byte{asSimpleString("hash"), asArray(asBulkString("f"), asBulkString("v"))}, map[string]string{"f": "v"}, "hash", false},
+		{"Set", [][]byte{asSimpleString("set"), asArray(asBulkString("m"))}, []string{"m"}, "set", false},
+		{"Sorted set", [][]byte{asSimpleString("zset"), asArray(asBulkString("m"))}, []string{"m"}, "zset", false},
+		{"Missing key", [][]byte{asSimpleString("none")}, nil, "none", false},
+		{"Unsupported type", [][]byte{asSimpleString("stream")}, nil, "stream", true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := New(context.Background(), "-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.pool <- fakeConn(t, tt.responses...)
+
+			got, gotType, err := client.GetAny(context.Background(), "k")
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAny() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetAny() got = %#v, want %#v", got, tt.want)
+			}
+			if gotType != tt.wantType {
+				t.Errorf("GetAny() gotType = %v, want %v", gotType, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestTTLMillis(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		ttl  time.Duration
+		want int64
+	}{
+		{1500 * time.Millisecond, 1500},
+		{time.Millisecond, 1},
+		// a positive ttl must not become 0, which would delete the key straight away
+		{time.Nanosecond, 1},
+		{999 * time.Microsecond, 1},
+		{1500 * time.Microsecond, 1},
+		{0, 0},
+		{-time.Second, -1000},
+	}
+	for _, tt := range tests {
+		if got := ttlMillis(tt.ttl); got != tt.want {
+			t.Errorf("ttlMillis(%v) = %v, want %v", tt.ttl, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// rpushCappedScript appends ARGV[2:] to the list, then trims it to the last ARGV[1] elements, in one step so no one
+// sees the list over length in between. Lua's unpack fails beyond about 8000 results, so values are pushed in chunks.
+const rpushCappedScript = `local n
+for i = 2, #ARGV, 1000 do
+	n = redis.call("RPUSH", KEYS[1], unpack(ARGV, i, math.min(i + 999, #ARGV)))
+end
+local max = tonumber(ARGV[1])
+if n > max then
+	redis.call("LTRIM", KEYS[1], -max, -1)
+	n = max
+end
+return n`
+
+// RPushCapped appends values to the list at key, then trims it to its last maxLen elements, as for a capped log. It
+// returns the length of the list afterwards. Both happen atomically in a Lua script, so the list is never seen
+// over maxLen.
+func (c *Client) RPushCapped(ctx context.Context, key string, maxLen int64, values ...string) (int64, error) {
+	result, cmd := rPushCappedCmd(key, maxLen, values)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func rPushCappedCmd(key string, maxLen int64, values []string) (*IntResult, pendingCmd) {
+	if maxLen < 1 {
+		result, cmd := newIntCmd()
+		cmd.fail(fmt.Errorf("redis: RPushCapped maxLen must be positive, got %v", maxLen))
+		return result, cmd
+	}
+	if len(values) == 0 {
+		result, cmd := newIntCmd()
+		cmd.fail(fmt.Errorf("redis: RPushCapped requires at least one value"))
+		return result, cmd
+	}
+	return newIntCmd(append([]string{"EVAL", rpushCappedScript, "1", key, strconv.FormatInt(maxLen, 10)}, values...)...)
+}
+
+// LTrim trims the list at key to the elements between start and stop, inclusive. Negative indices count from the tail,
+// so LTrim(ctx, key, -100, -1) keeps the last 100 elements.
+func (c *Client) LTrim(ctx context.Context, key string, start, stop int64) error {
+	result, cmd := lTrimCmd(key, start, stop)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func lTrimCmd(key string, start, stop int64) (*OKResult, pendingCmd) {
+	return newOKCmd("LTRIM", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+}
+
+// LInsert inserts value into the list at key, before or after the first occurrence of pivot.
+// It returns the new length of the list, -1 if pivot wasn't found, or 0 if key doesn't exist.
+func (c *Client) LInsert(ctx context.Context, key string, before bool, pivot, value string) (int64, error) {
+	result, cmd := lInsertCmd(key, before, pivot, value)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func lInsertCmd(key string, before bool, pivot, value string) (*IntResult, pendingCmd) {
+	where := "AFTER"
+	if before {
+		where = "BEFORE"
+	}
+	return newIntCmd("LINSERT", key, where, pivot, value)
+}
+
+// LSet sets the element at index of the list at key to value. Negative indices count from the tail.
+// An index out of range is an error.
+func (c *Client) LSet(ctx context.Context, key string, index int64, value string) error {
+	result, cmd := lSetCmd(key, index, value)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func lSetCmd(key string, index int64, value string) (*OKResult, pendingCmd) {
+	return newOKCmd("LSET", key, strconv.FormatInt(index, 10), value)
+}
+
+// LIndex returns the element at index of the list at key. Negative indices count from the tail.
+// exists is false if index is out of range or key doesn't exist.
+func (c *Client) LIndex(ctx context.Context, key string, index int64) (value string, exists bool, err error) {
+	result, cmd := lIndexCmd(key, index)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func lIndexCmd(key string, index int64) (*StringResult, pendingCmd) {
+	return newStringCmd("LINDEX", key, strconv.FormatInt(index, 10))
+}
+
+// LRem removes occurrences of value from the list at key and returns how many were removed.
+// A positive count removes up to count occurrences from head to tail, a negative one from tail to head,
+// and 0 removes them all.
+func (c *Client) LRem(ctx context.Context, key string, count int64, value string) (int64, error) {
+	result, cmd := lRemCmd(key, count, value)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func lRemCmd(key string, count int64, value string) (*IntResult, pendingCmd) {
+	return newIntCmd("LREM", key, strconv.FormatInt(count, 10), value)
+}
+
+// LRange returns the elements of the list at key between start and stop, inclusive. Negative indices count from
+// the tail, so LRange(ctx, key, 0, -1) returns the whole list.
+func (c *Client) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	result, cmd := lRangeCmd(key, start, stop)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func lRangeCmd(key string, start, stop int64) (*StringsResult, pendingCmd) {
+	return newStringsCmd("LRANGE", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+}
@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestListCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "RPushCapped",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.RPushCapped(ctx, "log", 100, "a", "b")
+			},
+			response:    asInteger(100),
+			wantCommand: []string{"EVAL", rpushCappedScript, "1", "log", "100", "a", "b"},
+			want:        int64(100),
+		},
+		{
+			name: "LTrim",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.LTrim(ctx, "l", -100, -1)
+			},
+			response:    okString,
+			wantCommand: []string{"LTRIM", "l", "-100", "-1"},
+		},
+		{
+			name: "LInsert before",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.LInsert(ctx, "l", true, "p", "v")
+			},
+			response:    asInteger(3),
+			wantCommand: []string{"LINSERT", "l", "BEFORE", "p", "v"},
+			want:        int64(3),
+		},
+		{
+			name: "LInsert after a missing pivot",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.LInsert(ctx, "l", false, "p", "v")
+			},
+			response:    asInteger(-1),
+			wantCommand: []string{"LINSERT", "l", "AFTER", "p", "v"},
+			want:        int64(-1),
+		},
+		{
+			name: "LSet",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.LSet(ctx, "l", -1, "v")
+			},
+			response:    okString,
+			wantCommand: []string{"LSET", "l", "-1", "v"},
+		},
+		{
+			name: "LSet out of range",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.LSet(ctx, "l", 10, "v")
+			},
+			response:    asSimpleErrorString("ERR index out of range"),
+			wantCommand: []string{"LSET", "l", "10", "v"},
+			wantErr:     true,
+		},
+		{
+			name: "LIndex",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				v, exists, err := c.LIndex(ctx, "l", 0)
+				return []interface{}{v, exists}, err
+			},
+			response:    asBulkString("v"),
+			wantCommand: []string{"LINDEX", "l", "0"},
+			want:        []interface{}{"v", true},
+		},
+		{
+			name: "LIndex out of range",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				v, exists, err := c.LIndex(ctx, "l", 10)
+				return []interface{}{v, exists}, err
+			},
+			response:    nullString,
+			wantCommand: []string{"LINDEX", "l", "10"},
+			want:        []interface{}{"", false},
+		},
+		{
+			name: "LRem",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.LRem(ctx, "l", -2, "v")
+			},
+			response:    asInteger(2),
+			wantCommand: []string{"LREM", "l", "-2", "v"},
+			want:        int64(2),
+		},
+		{
+			name: "LRange",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.LRange(ctx, "l", 0, -1)
+			},
+			response:    asArray(asBulkString("a"), asBulkString("b")),
+			wantCommand: []string{"LRANGE", "l", "0", "-1"},
+			want:        []string{"a", "b"},
+		},
+	})
+}
+
+func Test_Integration_RPushCapped_ManyValues(t *testing.T) {
+	c := integrationClient(t)
+	key := "RPushCapped"
+	if _, err := c.Do(context.Background(), "DEL", key); err != nil {
+		t.Fatal(err)
+	}
+	// more values than a single unpack in Lua can return
+	values := make([]string, 10000)
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+
+	got, err := c.RPushCapped(context.Background(), key, 9000, values...)
+
+	if err != nil {
+		t.Errorf("RPushCapped() error = %v", err)
+	}
+	if got != 9000 {
+		t.Errorf("RPushCapped() got = %v, want %v", got, 9000)
+	}
+	first, _, err := c.LIndex(context.Background(), key, 0)
+	if err != nil || first != "1000" {
+		t.Errorf("LIndex() got = %v, %v, want the oldest values trimmed", first, err)
+	}
+}
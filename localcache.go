@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithLocalCache keeps the values Get finds in an in-process cache of up to size keys, evicting the least recently
+// used, and serves them from there for ttl instead of asking Redis. It suits keys that rarely change, such as
+// configuration. Entries only expire with time: writes, even from this Client, aren't seen until then.
+// Missing keys aren't cached, and neither are reads on a Conn, which may have selected another database.
+// Stats counts the hits and misses.
+func WithLocalCache(size int, ttl time.Duration) Option {
+	return func(c *Client) {
+		if size > 0 && ttl > 0 {
+			c.localCache = &localCache{size: size, ttl: ttl, order: list.New(), entries: map[string]*list.Element{}}
+		}
+	}
+}
+
+// localCache is an LRU cache of GET replies, with a fixed time to live.
+type localCache struct {
+	size int
+	ttl  time.Duration
+
+	mu sync.Mutex
+	// order holds *cacheEntry, most recently used first
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	value   string
+	expires time.Time
+}
+
+// cachedGet is Get for a Client made WithLocalCache.
+func (c *Client) cachedGet(ctx context.Context, key string) (string, bool, error) {
+	if value, ok := c.localCache.get(key); ok {
+		atomic.AddInt64(&c.stats.localCacheHits, 1)
+		return value, true, nil
+	}
+	atomic.AddInt64(&c.stats.localCacheMisses, 1)
+	value, exists, err := c.get(ctx, key)
+	if err == nil && exists {
+		c.localCache.add(key, value)
+	}
+	return value, exists, err
+}
+
+func (l *localCache) get(key string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	elem, ok := l.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		l.order.Remove(elem)
+		delete(l.entries, key)
+		return "", false
+	}
+	l.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (l *localCache) add(key, value string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := &cacheEntry{key: key, value: value, expires: time.Now().Add(l.ttl)}
+	if elem, ok := l.entries[key]; ok {
+		elem.Value = entry
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.entries[key] = l.order.PushFront(entry)
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithLocalCache(t *testing.T) {
+	t.Parallel()
+	t.Run("Hits are served without asking Redis", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithLocalCache(10, time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// only one reply, so a second GET reaching Redis would fail
+		client.pool <- fakeConn(t, asBulkString("v"))
+
+		for i := 0; i < 2; i++ {
+			if got, exists, err := client.Get(context.Background(), "k"); got != "v" || !exists || err != nil {
+				t.Errorf("Get() #%v got = %v, %v, %v", i, got, exists, err)
+			}
+		}
+		if stats := client.Stats(); stats.LocalCacheHits != 1 || stats.LocalCacheMisses != 1 {
+			t.Errorf("Stats() = %+v, want 1 hit and 1 miss", stats)
+		}
+	})
+	t.Run("Expired entries are fetched again", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithLocalCache(10, 10*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asBulkString("old"), asBulkString("new"))
+
+		if got, _, err := client.Get(context.Background(), "k"); got != "old" || err != nil {
+			t.Fatalf("Get() got = %v, %v", got, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if got, _, err := client.Get(context.Background(), "k"); got != "new" || err != nil {
+			t.Errorf("Get() after expiry got = %v, %v, want %v", got, err, "new")
+		}
+	})
+	t.Run("Missing keys aren't cached", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithLocalCache(10, time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, nullString, asBulkString("v"))
+
+		if _, exists, err := client.Get(context.Background(), "k"); exists || err != nil {
+			t.Fatalf("Get() got = %v, %v", exists, err)
+		}
+		if got, _, err := client.Get(context.Background(), "k"); got != "v" || err != nil {
+			t.Errorf("Get() got = %v, %v, want %v", got, err, "v")
+		}
+		if stats := client.Stats(); stats.LocalCacheHits != 0 || stats.LocalCacheMisses != 2 {
+			t.Errorf("Stats() = %+v, want 0 hits and 2 misses", stats)
+		}
+	})
+}
+
+func TestLocalCache_Evicts(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1", WithLocalCache(2, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := client.localCache
+	cache.add("a", "1")
+	cache.add("b", "2")
+	// reading a makes b the least recently used
+	cache.get("a")
+	cache.add("c", "3")
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := cache.get(key); ok != want {
+			t.Errorf("get(%v) cached = %v, want %v", key, ok, want)
+		}
+	}
+}
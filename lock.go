@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"strconv"
+	"time"
+)
+
+// unlockScript deletes the lock only if it still holds our token, so we can't release a lock that expired and was
+// taken by someone else.
+const unlockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+
+// Lock tries to take the lock at key by setting it to token, only if it doesn't exist, expiring after ttl at
+// millisecond precision. It reports whether the lock was acquired. token should be unique to the caller, as Unlock
+// checks it. Lock doesn't wait for the lock to be released.
+func (c *Client) Lock(ctx context.Context, key, token string, ttl time.Duration) (acquired bool, err error) {
+	result, cmd := lockCmd(key, token, ttl)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func lockCmd(key, token string, ttl time.Duration) (*BoolResult, pendingCmd) {
+	r := &BoolResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  []string{"SET", key, token, "NX", "PX", strconv.FormatInt(ttlMillis(ttl), 10)},
+		types: "+$",
+		read: func(reader *bufio.Reader) error {
+			// +OK when set, a nil bulk string when the key exists
+			var reply interface{}
+			reply, r.err = readReply(reader)
+			r.value = reply == "OK"
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// Unlock releases the lock at key, but only if it is still held with token. It reports whether the lock was released,
+// false meaning it had already expired, or been taken by someone else since.
+func (c *Client) Unlock(ctx context.Context, key, token string) (bool, error) {
+	result, cmd := unlockCmd(key, token)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func unlockCmd(key, token string) (*BoolResult, pendingCmd) {
+	return newBoolCmd("EVAL", unlockScript, "1", key, token)
+}
@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "Lock acquired",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Lock(ctx, "lock", "token", 1500*time.Millisecond)
+			},
+			response:    okString,
+			wantCommand: []string{"SET", "lock", "token", "NX", "PX", "1500"},
+			want:        true,
+		},
+		{
+			name: "Lock held elsewhere",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Lock(ctx, "lock", "token", time.Second)
+			},
+			response:    []byte("$-1\r\n"),
+			wantCommand: []string{"SET", "lock", "token", "NX", "PX", "1000"},
+			want:        false,
+		},
+		{
+			name: "Unlock released",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Unlock(ctx, "lock", "token")
+			},
+			response:    asInteger(1),
+			wantCommand: []string{"EVAL", unlockScript, "1", "lock", "token"},
+			want:        true,
+		},
+		{
+			name: "Unlock with another token",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Unlock(ctx, "lock", "token")
+			},
+			response:    asInteger(0),
+			wantCommand: []string{"EVAL", unlockScript, "1", "lock", "token"},
+			want:        false,
+		},
+	})
+}
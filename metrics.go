@@ -0,0 +1,92 @@
+package redis
+
+import (
+	"expvar"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the latency histogram WithExpvar publishes. Slower commands count as "le_inf".
+var latencyBuckets = []struct {
+	name  string
+	bound time.Duration
+}{
+	{"le_1ms", time.Millisecond},
+	{"le_5ms", 5 * time.Millisecond},
+	{"le_10ms", 10 * time.Millisecond},
+	{"le_50ms", 50 * time.Millisecond},
+	{"le_100ms", 100 * time.Millisecond},
+	{"le_500ms", 500 * time.Millisecond},
+	{"le_1s", time.Second},
+}
+
+// WithExpvar publishes per command metrics through expvar, under "<prefix>.commands". For every command name it counts
+// calls and errors, and keeps a histogram of latencies in milliseconds, such as:
+//
+//	{"GET": {"calls": 10, "errors": 1, "latency": {"le_1ms": 8, "le_5ms": 2, ...}}}
+//
+// Clients with the same prefix share their metrics.
+func WithExpvar(prefix string) Option {
+	return func(c *Client) {
+		c.metrics = publishedMetrics(prefix + ".commands")
+	}
+}
+
+var (
+	metricsMu sync.Mutex
+	metrics   = make(map[string]*commandMetrics)
+)
+
+func publishedMetrics(name string) *commandMetrics {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if m, ok := metrics[name]; ok {
+		return m
+	}
+	m := &commandMetrics{commands: expvar.NewMap(name)}
+	metrics[name] = m
+	return m
+}
+
+type commandMetrics struct {
+	mu       sync.Mutex
+	commands *expvar.Map
+}
+
+func (m *commandMetrics) record(cmds [][]string, elapsed time.Duration, err error) {
+	for _, args := range cmds {
+		if len(args) == 0 {
+			continue
+		}
+		command := m.command(strings.ToUpper(args[0]))
+		command.Add("calls", 1)
+		if err != nil {
+			command.Add("errors", 1)
+		}
+		latency := command.Get("latency").(*expvar.Map)
+		latency.Add(bucketFor(elapsed), 1)
+	}
+}
+
+// command returns the metrics of a single command, creating them the first time it is seen.
+func (m *commandMetrics) command(name string) *expvar.Map {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if command, ok := m.commands.Get(name).(*expvar.Map); ok {
+		return command
+	}
+	command := new(expvar.Map).Init()
+	command.Set("latency", new(expvar.Map).Init())
+	m.commands.Set(name, command)
+	return command
+}
+
+func bucketFor(elapsed time.Duration) string {
+	for _, bucket := range latencyBuckets {
+		if elapsed <= bucket.bound {
+			return bucket.name
+		}
+	}
+	return "le_inf"
+}
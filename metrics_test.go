@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"context"
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestWithExpvar(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1", WithExpvar("redistest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pool <- fakeConn(t, okString, asSimpleErrorString("ERR nope"))
+
+	_ = client.Set(context.Background(), "Foo", "bar")
+	_ = client.Set(context.Background(), "Foo", "bar")
+
+	set, ok := expvar.Get("redistest.commands").(*expvar.Map).Get("SET").(*expvar.Map)
+	if !ok {
+		t.Fatalf("SET metrics were not published")
+	}
+	if got := set.Get("calls").String(); got != "2" {
+		t.Errorf("calls got = %v, want %v", got, 2)
+	}
+	if got := set.Get("errors").String(); got != "1" {
+		t.Errorf("errors got = %v, want %v", got, 1)
+	}
+
+	if _, err := New(context.Background(), "-1", WithExpvar("redistest")); err != nil {
+		t.Errorf("A second Client with the same prefix should share the metrics, got %v", err)
+	}
+}
+
+func TestBucketFor(t *testing.T) {
+	t.Parallel()
+	for elapsed, want := range map[time.Duration]string{
+		time.Microsecond:       "le_1ms",
+		time.Millisecond:       "le_1ms",
+		3 * time.Millisecond:   "le_5ms",
+		700 * time.Millisecond: "le_1s",
+		time.Minute:            "le_inf",
+	} {
+		if got := bucketFor(elapsed); got != want {
+			t.Errorf("bucketFor(%v) got = %v, want %v", elapsed, got, want)
+		}
+	}
+}
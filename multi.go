@@ -0,0 +1,479 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTxAborted is returned by Multi.Exec when EXEC was aborted because a WATCHed key changed.
+var ErrTxAborted = errors.New("redis: transaction aborted")
+
+// A Multi queues commands and runs them atomically as a MULTI/EXEC transaction.
+// It should be constructed with Client.Multi, and is not safe for concurrent use.
+//
+// Each queuing method mirrors the Client method of the same name, but returns a result to read after Exec.
+type Multi struct {
+	client *Client
+	cmds   []pendingCmd
+}
+
+// Multi returns an empty transaction.
+func (c *Client) Multi() *Multi {
+	return &Multi{client: c}
+}
+
+// Exec sends the queued commands wrapped in MULTI and EXEC on a single connection, and reads their replies into their
+// results. If Redis rejects a command while queueing, the whole transaction is discarded and that error is returned.
+// Otherwise it returns the first error of any command. The Multi is empty afterwards and may be reused.
+func (m *Multi) Exec(ctx context.Context) error {
+	cmds := m.cmds
+	m.cmds = nil
+	if len(cmds) == 0 {
+		return nil
+	}
+	args := make([][]string, 0, len(cmds)+2)
+	args = append(args, []string{"MULTI"})
+	for _, cmd := range cmds {
+		args = append(args, cmd.args)
+	}
+	args = append(args, []string{"EXEC"})
+
+	var txErr error
+	var replied int
+	err := m.client.execPipeline(ctx, args, func(reader *bufio.Reader) error {
+		txErr, replied = nil, 0
+		if err := expectOK(reader); err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			err := expectQueued(reader)
+			if err != nil && !isRedisError(err) {
+				return err
+			}
+			if err != nil && txErr == nil {
+				txErr = m.client.withCommand(err, cmd.args)
+			}
+		}
+		msgType, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch msgType {
+		case '-':
+			// EXECABORT, caused by an error while queueing, which is more useful to report
+			execErr := readErrorMessage(reader)
+			if txErr == nil {
+				txErr = execErr
+			}
+			return nil
+		case '*':
+		default:
+			return fmt.Errorf("redis: unexpected message type %v", msgType)
+		}
+		size, err := readInteger(reader)
+		if err != nil {
+			return err
+		}
+		if size == -1 {
+			txErr = ErrTxAborted
+			return nil
+		}
+		if size != int64(len(cmds)) {
+			return fmt.Errorf("redis: expected %v replies from EXEC but got %v", len(cmds), size)
+		}
+		for _, cmd := range cmds {
+			err := m.client.strictRead(cmd.types, cmd.read)(reader)
+			replied++
+			if err != nil && !isRedisError(err) {
+				return err
+			}
+			if err != nil {
+				err = m.client.withCommand(err, cmd.args)
+				cmd.fail(err)
+			}
+			if err != nil && txErr == nil {
+				txErr = err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = txErr
+	}
+	if err != nil {
+		// commands that never got a reply of their own share the error of the transaction
+		for _, cmd := range cmds[replied:] {
+			cmd.fail(err)
+		}
+	}
+	return err
+}
+
+// expectQueued reads the reply to a command sent inside MULTI, which is QUEUED unless Redis rejected it.
+func expectQueued(reader *bufio.Reader) error {
+	msgType, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch msgType {
+	case '-':
+		return readErrorMessage(reader)
+	case '+':
+		queued, err := readSimpleString(reader)
+		if err != nil {
+			return err
+		}
+		if queued != "QUEUED" {
+			return fmt.Errorf("redis: expected QUEUED from Redis but got: %v", queued)
+		}
+		return nil
+	default:
+		return fmt.Errorf("redis: unexpected message type %v", msgType)
+	}
+}
+
+// Discard drops every queued command without sending it, leaving the Multi empty and ready for reuse. Their results
+// keep returning ErrNotExecuted. Nothing reaches Redis before Exec, so there is no DISCARD to send.
+func (m *Multi) Discard() {
+	m.cmds = nil
+}
+
+func (m *Multi) queue(cmd pendingCmd) {
+	if cmd.args == nil {
+		return
+	}
+	m.cmds = append(m.cmds, cmd)
+}
+
+// Set queues a Set.
+func (m *Multi) Set(key string, value string) *OKResult {
+	result, cmd := setCmd(key, value)
+	m.queue(cmd)
+	return result
+}
+
+// Get queues a Get. It always reads from Redis, bypassing WithLocalCache.
+func (m *Multi) Get(key string) *StringResult {
+	result, cmd := getCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// GetDel queues a GetDel.
+func (m *Multi) GetDel(key string) *StringResult {
+	result, cmd := getDelCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// GetEx queues a GetEx.
+func (m *Multi) GetEx(key string, ttl time.Duration) *StringResult {
+	result, cmd := getExCmd(key, ttl)
+	m.queue(cmd)
+	return result
+}
+
+// Incr queues an Incr.
+func (m *Multi) Incr(key string) *IntResult {
+	result, cmd := incrCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// IncrByFloat queues an IncrByFloat.
+func (m *Multi) IncrByFloat(key string, delta float64) *FloatResult {
+	result, cmd := incrByFloatCmd(key, delta)
+	m.queue(cmd)
+	return result
+}
+
+// SetEx queues a SetEx.
+func (m *Multi) SetEx(key, value string, seconds int64) *OKResult {
+	result, cmd := setExCmd(key, value, seconds)
+	m.queue(cmd)
+	return result
+}
+
+// PSetEx queues a PSetEx.
+func (m *Multi) PSetEx(key, value string, millis int64) *OKResult {
+	result, cmd := pSetExCmd(key, value, millis)
+	m.queue(cmd)
+	return result
+}
+
+// MGet queues an MGet.
+func (m *Multi) MGet(keys ...string) *ValuesResult {
+	result, cmd := mgetCmd(keys)
+	m.queue(cmd)
+	return result
+}
+
+// Expire queues an Expire.
+func (m *Multi) Expire(key string, ttl time.Duration) *BoolResult {
+	result, cmd := expireCmd(key, ttl)
+	m.queue(cmd)
+	return result
+}
+
+// ExpireTime queues an ExpireTime.
+func (m *Multi) ExpireTime(key string) *ExpiryResult {
+	result, cmd := expireTimeCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// PExpireTime queues a PExpireTime.
+func (m *Multi) PExpireTime(key string) *ExpiryResult {
+	result, cmd := pExpireTimeCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// Rename queues a Rename.
+func (m *Multi) Rename(src, dst string) *OKResult {
+	result, cmd := renameCmd(src, dst)
+	m.queue(cmd)
+	return result
+}
+
+// Type queues a Type.
+func (m *Multi) Type(key string) *StatusResult {
+	result, cmd := typeCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// BitPos queues a BitPos.
+func (m *Multi) BitPos(key string, bit int, opts BitPosOptions) *IntResult {
+	result, cmd := bitPosCmd(key, bit, opts)
+	m.queue(cmd)
+	return result
+}
+
+// BitField queues a BitField.
+func (m *Multi) BitField(key string, ops ...BitFieldOp) *IntsResult {
+	result, cmd := bitFieldCmd(key, ops)
+	m.queue(cmd)
+	return result
+}
+
+// Lock queues a Lock.
+func (m *Multi) Lock(key, token string, ttl time.Duration) *BoolResult {
+	result, cmd := lockCmd(key, token, ttl)
+	m.queue(cmd)
+	return result
+}
+
+// Unlock queues an Unlock.
+func (m *Multi) Unlock(key, token string) *BoolResult {
+	result, cmd := unlockCmd(key, token)
+	m.queue(cmd)
+	return result
+}
+
+// HSetNX queues an HSetNX.
+func (m *Multi) HSetNX(key, field, value string) *BoolResult {
+	result, cmd := hSetNXCmd(key, field, value)
+	m.queue(cmd)
+	return result
+}
+
+// HSetMulti queues an HSetMulti.
+func (m *Multi) HSetMulti(key string, fields map[string]string) *IntResult {
+	result, cmd := hSetMultiCmd(key, fields)
+	m.queue(cmd)
+	return result
+}
+
+// HIncrByFloat queues an HIncrByFloat.
+func (m *Multi) HIncrByFloat(key, field string, delta float64) *FloatResult {
+	result, cmd := hIncrByFloatCmd(key, field, delta)
+	m.queue(cmd)
+	return result
+}
+
+// HRandField queues an HRandField.
+func (m *Multi) HRandField(key string, count int64) *StringsResult {
+	result, cmd := hRandFieldCmd(key, count)
+	m.queue(cmd)
+	return result
+}
+
+// HGetAll queues an HGetAll.
+func (m *Multi) HGetAll(key string) *MapResult {
+	result, cmd := hGetAllCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// HLen queues an HLen.
+func (m *Multi) HLen(key string) *IntResult {
+	result, cmd := hLenCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// HKeys queues an HKeys.
+func (m *Multi) HKeys(key string) *StringsResult {
+	result, cmd := hKeysCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// HVals queues an HVals.
+func (m *Multi) HVals(key string) *StringsResult {
+	result, cmd := hValsCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// RPushCapped queues an RPushCapped.
+func (m *Multi) RPushCapped(key string, maxLen int64, values ...string) *IntResult {
+	result, cmd := rPushCappedCmd(key, maxLen, values)
+	m.queue(cmd)
+	return result
+}
+
+// LTrim queues an LTrim.
+func (m *Multi) LTrim(key string, start, stop int64) *OKResult {
+	result, cmd := lTrimCmd(key, start, stop)
+	m.queue(cmd)
+	return result
+}
+
+// LInsert queues an LInsert.
+func (m *Multi) LInsert(key string, before bool, pivot, value string) *IntResult {
+	result, cmd := lInsertCmd(key, before, pivot, value)
+	m.queue(cmd)
+	return result
+}
+
+// LSet queues an LSet.
+func (m *Multi) LSet(key string, index int64, value string) *OKResult {
+	result, cmd := lSetCmd(key, index, value)
+	m.queue(cmd)
+	return result
+}
+
+// LIndex queues an LIndex.
+func (m *Multi) LIndex(key string, index int64) *StringResult {
+	result, cmd := lIndexCmd(key, index)
+	m.queue(cmd)
+	return result
+}
+
+// LRem queues an LRem.
+func (m *Multi) LRem(key string, count int64, value string) *IntResult {
+	result, cmd := lRemCmd(key, count, value)
+	m.queue(cmd)
+	return result
+}
+
+// LRange queues an LRange.
+func (m *Multi) LRange(key string, start, stop int64) *StringsResult {
+	result, cmd := lRangeCmd(key, start, stop)
+	m.queue(cmd)
+	return result
+}
+
+// SInterStore queues an SInterStore.
+func (m *Multi) SInterStore(dest string, keys ...string) *IntResult {
+	result, cmd := sInterStoreCmd(dest, keys)
+	m.queue(cmd)
+	return result
+}
+
+// SUnionStore queues an SUnionStore.
+func (m *Multi) SUnionStore(dest string, keys ...string) *IntResult {
+	result, cmd := sUnionStoreCmd(dest, keys)
+	m.queue(cmd)
+	return result
+}
+
+// SDiffStore queues an SDiffStore.
+func (m *Multi) SDiffStore(dest string, keys ...string) *IntResult {
+	result, cmd := sDiffStoreCmd(dest, keys)
+	m.queue(cmd)
+	return result
+}
+
+// SUnion queues an SUnion.
+func (m *Multi) SUnion(keys ...string) *StringsResult {
+	result, cmd := sUnionCmd(keys)
+	m.queue(cmd)
+	return result
+}
+
+// SInter queues an SInter.
+func (m *Multi) SInter(keys ...string) *StringsResult {
+	result, cmd := sInterCmd(keys)
+	m.queue(cmd)
+	return result
+}
+
+// SDiff queues an SDiff.
+func (m *Multi) SDiff(keys ...string) *StringsResult {
+	result, cmd := sDiffCmd(keys)
+	m.queue(cmd)
+	return result
+}
+
+// SPop queues an SPop.
+func (m *Multi) SPop(key string, count int64) *StringsResult {
+	result, cmd := sPopCmd(key, count)
+	m.queue(cmd)
+	return result
+}
+
+// SRandMember queues an SRandMember.
+func (m *Multi) SRandMember(key string, count int64) *StringsResult {
+	result, cmd := sRandMemberCmd(key, count)
+	m.queue(cmd)
+	return result
+}
+
+// SMembers queues an SMembers.
+func (m *Multi) SMembers(key string) *StringsResult {
+	result, cmd := sMembersCmd(key)
+	m.queue(cmd)
+	return result
+}
+
+// SMove queues an SMove.
+func (m *Multi) SMove(src, dst, member string) *BoolResult {
+	result, cmd := sMoveCmd(src, dst, member)
+	m.queue(cmd)
+	return result
+}
+
+// SInterCard queues an SInterCard.
+func (m *Multi) SInterCard(limit int64, keys ...string) *IntResult {
+	result, cmd := sInterCardCmd(limit, keys)
+	m.queue(cmd)
+	return result
+}
+
+// ZRange queues a ZRange.
+func (m *Multi) ZRange(key string, start, stop int64) *StringsResult {
+	result, cmd := zRangeCmd(key, start, stop)
+	m.queue(cmd)
+	return result
+}
+
+// ZInterCard queues a ZInterCard.
+func (m *Multi) ZInterCard(limit int64, keys ...string) *IntResult {
+	result, cmd := zInterCardCmd(limit, keys)
+	m.queue(cmd)
+	return result
+}
+
+// ZRangeByLex queues a ZRangeByLex.
+func (m *Multi) ZRangeByLex(key, min, max string, opts LexRangeOptions) *StringsResult {
+	result, cmd := zRangeByLexCmd(key, min, max, opts)
+	m.queue(cmd)
+	return result
+}
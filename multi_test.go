@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMulti_Exec(t *testing.T) {
+	t.Parallel()
+	queued := asSimpleString("QUEUED")
+	tests := []struct {
+		name        string
+		response    [][]byte
+		wantErr     error
+		wantIncr    int64
+		wantIncrErr error
+		// wantCommand is the Command of the Error from Exec, if any
+		wantCommand string
+	}{
+		{
+			"Commands run inside MULTI/EXEC",
+			[][]byte{okString, queued, queued, asArray(asInteger(1), asInteger(2))},
+			nil,
+			2,
+			nil,
+			"",
+		},
+		{
+			"A changed WATCHed key aborts the transaction",
+			[][]byte{okString, queued, queued, []byte("*-1\r\n")},
+			ErrTxAborted,
+			0,
+			ErrTxAborted,
+			"",
+		},
+		{
+			"An error while queueing discards the transaction",
+			[][]byte{okString, queued, asSimpleErrorString("ERR wrong number of arguments"),
+				asSimpleErrorString("EXECABORT Transaction discarded because of previous errors.")},
+			errors.New("ERR wrong number of arguments"),
+			0,
+			errors.New("ERR wrong number of arguments"),
+			"INCR a",
+		},
+		{
+			"Errors from EXEC name their command",
+			[][]byte{okString, queued, queued, asArray(asInteger(1), asSimpleErrorString("ERR value is not an integer"))},
+			errors.New("ERR value is not an integer"),
+			0,
+			errors.New("ERR value is not an integer"),
+			"INCR a",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := New(context.Background(), "-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var response []byte
+			for _, r := range tt.response {
+				response = append(response, r...)
+			}
+			conn, requests := recordingConn(t, response)
+			client.pool <- conn
+			m := client.Multi()
+			m.Incr("a")
+			incr := m.Incr("a")
+
+			err = m.Exec(context.Background())
+
+			if (err == nil) != (tt.wantErr == nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("Exec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var redisErr Error
+			if tt.wantCommand != "" && (!errors.As(err, &redisErr) || redisErr.Command != tt.wantCommand) {
+				t.Errorf("Exec() error = %#v, want an Error from %q", err, tt.wantCommand)
+			}
+			got, gotErr := incr.Result()
+			if got != tt.wantIncr || (gotErr == nil) != (tt.wantIncrErr == nil) {
+				t.Errorf("Incr() got = %v, %v, want %v, %v", got, gotErr, tt.wantIncr, tt.wantIncrErr)
+			}
+			want := string(command("MULTI")) + string(command("INCR", "a")) + string(command("INCR", "a")) + string(command("EXEC"))
+			if got := <-requests; got != want {
+				t.Errorf("Exec() sent = %q, want %q", got, want)
+			}
+			if len(client.pool) != 1 {
+				t.Errorf("Should have put the conn back, pool has %v", len(client.pool))
+			}
+		})
+	}
+}
+
+func TestMulti_Discard(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := client.Multi()
+	incr := m.Incr("a")
+
+	m.Discard()
+
+	// nothing is left to send, so Exec doesn't even need a connection
+	if err := m.Exec(context.Background()); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if _, err := incr.Result(); !errors.Is(err, ErrNotExecuted) {
+		t.Errorf("Result() of a discarded command error = %v, wantErr %v", err, ErrNotExecuted)
+	}
+}
+
+func TestClient_UnexpectedQueued(t *testing.T) {
+	t.Parallel()
+	t.Run("A pooled connection left in a transaction is discarded", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asSimpleString("QUEUED"))
+
+		_, _, err = client.Get(context.Background(), "Foo")
+
+		if !errors.Is(err, ErrUnexpectedQueued) {
+			t.Errorf("Get() error = %v, want %v", err, ErrUnexpectedQueued)
+		}
+		if len(client.pool) != 0 {
+			t.Errorf("the connection should have been discarded")
+		}
+	})
+	t.Run("A Conn may queue commands itself", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, okString, asSimpleString("QUEUED"))
+
+		err = client.Session(context.Background(), func(conn *Conn) error {
+			if _, err := conn.Do(context.Background(), "MULTI"); err != nil {
+				return err
+			}
+			got, err := conn.Do(context.Background(), "SET", "Foo", "bar")
+			if got != "QUEUED" {
+				t.Errorf("Do() got = %v", got)
+			}
+			return err
+		})
+
+		if err != nil {
+			t.Errorf("Session() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,676 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrNotExecuted is returned by the result of a queued command until its Pipeline or Multi has been executed.
+var ErrNotExecuted = errors.New("redis: command has not been executed yet")
+
+// A pendingCmd is a command along with how to decode its reply into a result. Every command that can be pipelined
+// is defined once as a pendingCmd, which the Client, Pipeline and Multi methods all share.
+type pendingCmd struct {
+	// args is nil for a command settled without being sent, such as one with invalid arguments
+	args []string
+	// types are the reply types read expects, checked before calling it with WithStrictReplies
+	types string
+	// read decodes the reply into the result, returning the same error the result now holds
+	read func(reader *bufio.Reader) error
+	// fail records an error for a command whose reply was never read
+	fail func(err error)
+}
+
+// StringResult is the deferred result of a queued command replying with a string.
+type StringResult struct {
+	value  string
+	exists bool
+	err    error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *StringResult) Result() (value string, exists bool, err error) {
+	return r.value, r.exists, r.err
+}
+
+func newStringCmd(args ...string) (*StringResult, pendingCmd) {
+	r := &StringResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  args,
+		types: "$",
+		read: func(reader *bufio.Reader) error {
+			r.value, r.exists, r.err = readBulkStringReply(reader)
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// IntResult is the deferred result of a queued command replying with an integer.
+type IntResult struct {
+	value int64
+	err   error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *IntResult) Result() (int64, error) {
+	return r.value, r.err
+}
+
+func newIntCmd(args ...string) (*IntResult, pendingCmd) {
+	r := &IntResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  args,
+		types: ":",
+		read: func(reader *bufio.Reader) error {
+			r.value, r.err = readIntegerReply(reader)
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// BoolResult is the deferred result of a queued command replying with 1 or 0.
+type BoolResult struct {
+	value bool
+	err   error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *BoolResult) Result() (bool, error) {
+	return r.value, r.err
+}
+
+func newBoolCmd(args ...string) (*BoolResult, pendingCmd) {
+	r := &BoolResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  args,
+		types: ":",
+		read: func(reader *bufio.Reader) error {
+			var n int64
+			n, r.err = readIntegerReply(reader)
+			r.value = n == 1
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// OKResult is the deferred result of a queued command replying with OK.
+type OKResult struct {
+	err error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *OKResult) Result() error {
+	return r.err
+}
+
+func newOKCmd(args ...string) (*OKResult, pendingCmd) {
+	r := &OKResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  args,
+		types: "+",
+		read: func(reader *bufio.Reader) error {
+			r.err = expectOK(reader)
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// StatusResult is the deferred result of a queued command replying with a status message.
+type StatusResult struct {
+	value string
+	err   error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *StatusResult) Result() (string, error) {
+	return r.value, r.err
+}
+
+func newStatusCmd(args ...string) (*StatusResult, pendingCmd) {
+	r := &StatusResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  args,
+		types: "+",
+		read: func(reader *bufio.Reader) error {
+			r.value, r.err = readSimpleStringReply(reader)
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// FloatResult is the deferred result of a queued command replying with a float, such as the INCRBYFLOAT family.
+type FloatResult struct {
+	value float64
+	err   error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *FloatResult) Result() (float64, error) {
+	return r.value, r.err
+}
+
+func newFloatCmd(args ...string) (*FloatResult, pendingCmd) {
+	r := &FloatResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  args,
+		types: "$",
+		read: func(reader *bufio.Reader) error {
+			var value string
+			value, _, r.err = readBulkStringReply(reader)
+			if r.err == nil {
+				r.value, r.err = strconv.ParseFloat(value, 64)
+			}
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// StringsResult is the deferred result of a queued command replying with an array of strings.
+type StringsResult struct {
+	values []string
+	err    error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *StringsResult) Result() ([]string, error) {
+	return r.values, r.err
+}
+
+func newStringsCmd(args ...string) (*StringsResult, pendingCmd) {
+	r := &StringsResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  args,
+		types: "*",
+		read: func(reader *bufio.Reader) error {
+			r.values, r.err = readStringsReply(reader)
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// MapResult is the deferred result of a queued command replying with pairs of strings, such as HGETALL.
+type MapResult struct {
+	value map[string]string
+	err   error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *MapResult) Result() (map[string]string, error) {
+	return r.value, r.err
+}
+
+func newMapCmd(args ...string) (*MapResult, pendingCmd) {
+	r := &MapResult{err: ErrNotExecuted}
+	return r, pendingCmd{
+		args:  args,
+		types: "*",
+		read: func(reader *bufio.Reader) error {
+			var flat []string
+			flat, r.err = readStringsReply(reader)
+			if r.err == nil {
+				r.value, r.err = pairs(flat)
+			}
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// ValuesResult is the deferred result of a queued MGet.
+type ValuesResult struct {
+	values []string
+	exists []bool
+	err    error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *ValuesResult) Result() (values []string, exists []bool, err error) {
+	return r.values, r.exists, r.err
+}
+
+// IntsResult is the deferred result of a queued BitField.
+type IntsResult struct {
+	values []*int64
+	err    error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *IntsResult) Result() ([]*int64, error) {
+	return r.values, r.err
+}
+
+// ExpiryResult is the deferred result of a queued ExpireTime or PExpireTime.
+type ExpiryResult struct {
+	expiry time.Time
+	exists bool
+	err    error
+}
+
+// Result returns the reply, in the same shape as the matching Client method.
+func (r *ExpiryResult) Result() (expiry time.Time, exists bool, err error) {
+	return r.expiry, r.exists, r.err
+}
+
+// execCmd runs a single pendingCmd on the Client. The caller reads the outcome from the result it belongs to.
+func (c *Client) execCmd(ctx context.Context, cmd pendingCmd) {
+	if cmd.args == nil {
+		return
+	}
+	err := c.exec(ctx, cmd.args, c.strictRead(cmd.types, cmd.read))
+	if err != nil {
+		cmd.fail(err)
+	}
+}
+
+// A Pipeline queues commands and sends them to Redis in one go, saving a round trip per command.
+// It should be constructed with Client.Pipeline. Unlike Multi, the commands are not atomic: other clients' commands
+// may run in between. A Pipeline is not safe for concurrent use.
+//
+// Each queuing method mirrors the Client method of the same name, but returns a result to read after Exec.
+type Pipeline struct {
+	client *Client
+	cmds   []pendingCmd
+}
+
+// Pipeline returns an empty Pipeline.
+func (c *Client) Pipeline() *Pipeline {
+	return &Pipeline{client: c}
+}
+
+// Exec sends every queued command on a single connection and reads their replies into their results.
+// It returns the first error of any command. The Pipeline is empty afterwards and may be reused.
+func (p *Pipeline) Exec(ctx context.Context) error {
+	cmds := p.cmds
+	p.cmds = nil
+	if len(cmds) == 0 {
+		return nil
+	}
+	return p.client.execPendingCmds(ctx, cmds)
+}
+
+// Discard drops every queued command without sending it, leaving the Pipeline empty and ready for reuse. Their results
+// keep returning ErrNotExecuted. A Pipeline only borrows a connection during Exec, so an abandoned one holds no
+// connection either way, but Discard releases the queued commands straight away.
+func (p *Pipeline) Discard() {
+	p.cmds = nil
+}
+
+func (c *Client) execPendingCmds(ctx context.Context, cmds []pendingCmd) error {
+	args := make([][]string, len(cmds))
+	for i, cmd := range cmds {
+		args[i] = cmd.args
+	}
+	var firstErr error
+	var replied int
+	err := c.execPipeline(ctx, args, func(reader *bufio.Reader) error {
+		firstErr, replied = nil, 0
+		for _, cmd := range cmds {
+			err := c.strictRead(cmd.types, cmd.read)(reader)
+			replied++
+			if err != nil && !isRedisError(err) {
+				return err
+			}
+			if err != nil {
+				err = c.withCommand(err, cmd.args)
+				cmd.fail(err)
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for _, cmd := range cmds[replied:] {
+			cmd.fail(err)
+		}
+		return err
+	}
+	return firstErr
+}
+
+func (p *Pipeline) queue(cmd pendingCmd) {
+	if cmd.args == nil {
+		return
+	}
+	p.cmds = append(p.cmds, cmd)
+}
+
+// Set queues a Set.
+func (p *Pipeline) Set(key string, value string) *OKResult {
+	result, cmd := setCmd(key, value)
+	p.queue(cmd)
+	return result
+}
+
+// Get queues a Get. It always reads from Redis, bypassing WithLocalCache.
+func (p *Pipeline) Get(key string) *StringResult {
+	result, cmd := getCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// GetDel queues a GetDel.
+func (p *Pipeline) GetDel(key string) *StringResult {
+	result, cmd := getDelCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// GetEx queues a GetEx.
+func (p *Pipeline) GetEx(key string, ttl time.Duration) *StringResult {
+	result, cmd := getExCmd(key, ttl)
+	p.queue(cmd)
+	return result
+}
+
+// Incr queues an Incr.
+func (p *Pipeline) Incr(key string) *IntResult {
+	result, cmd := incrCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// IncrByFloat queues an IncrByFloat.
+func (p *Pipeline) IncrByFloat(key string, delta float64) *FloatResult {
+	result, cmd := incrByFloatCmd(key, delta)
+	p.queue(cmd)
+	return result
+}
+
+// SetEx queues a SetEx.
+func (p *Pipeline) SetEx(key, value string, seconds int64) *OKResult {
+	result, cmd := setExCmd(key, value, seconds)
+	p.queue(cmd)
+	return result
+}
+
+// PSetEx queues a PSetEx.
+func (p *Pipeline) PSetEx(key, value string, millis int64) *OKResult {
+	result, cmd := pSetExCmd(key, value, millis)
+	p.queue(cmd)
+	return result
+}
+
+// MGet queues an MGet.
+func (p *Pipeline) MGet(keys ...string) *ValuesResult {
+	result, cmd := mgetCmd(keys)
+	p.queue(cmd)
+	return result
+}
+
+// Expire queues an Expire.
+func (p *Pipeline) Expire(key string, ttl time.Duration) *BoolResult {
+	result, cmd := expireCmd(key, ttl)
+	p.queue(cmd)
+	return result
+}
+
+// ExpireTime queues an ExpireTime.
+func (p *Pipeline) ExpireTime(key string) *ExpiryResult {
+	result, cmd := expireTimeCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// PExpireTime queues a PExpireTime.
+func (p *Pipeline) PExpireTime(key string) *ExpiryResult {
+	result, cmd := pExpireTimeCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// Rename queues a Rename.
+func (p *Pipeline) Rename(src, dst string) *OKResult {
+	result, cmd := renameCmd(src, dst)
+	p.queue(cmd)
+	return result
+}
+
+// Type queues a Type.
+func (p *Pipeline) Type(key string) *StatusResult {
+	result, cmd := typeCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// BitPos queues a BitPos.
+func (p *Pipeline) BitPos(key string, bit int, opts BitPosOptions) *IntResult {
+	result, cmd := bitPosCmd(key, bit, opts)
+	p.queue(cmd)
+	return result
+}
+
+// BitField queues a BitField.
+func (p *Pipeline) BitField(key string, ops ...BitFieldOp) *IntsResult {
+	result, cmd := bitFieldCmd(key, ops)
+	p.queue(cmd)
+	return result
+}
+
+// Lock queues a Lock.
+func (p *Pipeline) Lock(key, token string, ttl time.Duration) *BoolResult {
+	result, cmd := lockCmd(key, token, ttl)
+	p.queue(cmd)
+	return result
+}
+
+// Unlock queues an Unlock.
+func (p *Pipeline) Unlock(key, token string) *BoolResult {
+	result, cmd := unlockCmd(key, token)
+	p.queue(cmd)
+	return result
+}
+
+// HSetNX queues an HSetNX.
+func (p *Pipeline) HSetNX(key, field, value string) *BoolResult {
+	result, cmd := hSetNXCmd(key, field, value)
+	p.queue(cmd)
+	return result
+}
+
+// HSetMulti queues an HSetMulti.
+func (p *Pipeline) HSetMulti(key string, fields map[string]string) *IntResult {
+	result, cmd := hSetMultiCmd(key, fields)
+	p.queue(cmd)
+	return result
+}
+
+// HIncrByFloat queues an HIncrByFloat.
+func (p *Pipeline) HIncrByFloat(key, field string, delta float64) *FloatResult {
+	result, cmd := hIncrByFloatCmd(key, field, delta)
+	p.queue(cmd)
+	return result
+}
+
+// HRandField queues an HRandField.
+func (p *Pipeline) HRandField(key string, count int64) *StringsResult {
+	result, cmd := hRandFieldCmd(key, count)
+	p.queue(cmd)
+	return result
+}
+
+// HGetAll queues an HGetAll.
+func (p *Pipeline) HGetAll(key string) *MapResult {
+	result, cmd := hGetAllCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// HLen queues an HLen.
+func (p *Pipeline) HLen(key string) *IntResult {
+	result, cmd := hLenCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// HKeys queues an HKeys.
+func (p *Pipeline) HKeys(key string) *StringsResult {
+	result, cmd := hKeysCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// HVals queues an HVals.
+func (p *Pipeline) HVals(key string) *StringsResult {
+	result, cmd := hValsCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// RPushCapped queues an RPushCapped.
+func (p *Pipeline) RPushCapped(key string, maxLen int64, values ...string) *IntResult {
+	result, cmd := rPushCappedCmd(key, maxLen, values)
+	p.queue(cmd)
+	return result
+}
+
+// LTrim queues an LTrim.
+func (p *Pipeline) LTrim(key string, start, stop int64) *OKResult {
+	result, cmd := lTrimCmd(key, start, stop)
+	p.queue(cmd)
+	return result
+}
+
+// LInsert queues an LInsert.
+func (p *Pipeline) LInsert(key string, before bool, pivot, value string) *IntResult {
+	result, cmd := lInsertCmd(key, before, pivot, value)
+	p.queue(cmd)
+	return result
+}
+
+// LSet queues an LSet.
+func (p *Pipeline) LSet(key string, index int64, value string) *OKResult {
+	result, cmd := lSetCmd(key, index, value)
+	p.queue(cmd)
+	return result
+}
+
+// LIndex queues an LIndex.
+func (p *Pipeline) LIndex(key string, index int64) *StringResult {
+	result, cmd := lIndexCmd(key, index)
+	p.queue(cmd)
+	return result
+}
+
+// LRem queues an LRem.
+func (p *Pipeline) LRem(key string, count int64, value string) *IntResult {
+	result, cmd := lRemCmd(key, count, value)
+	p.queue(cmd)
+	return result
+}
+
+// LRange queues an LRange.
+func (p *Pipeline) LRange(key string, start, stop int64) *StringsResult {
+	result, cmd := lRangeCmd(key, start, stop)
+	p.queue(cmd)
+	return result
+}
+
+// SInterStore queues an SInterStore.
+func (p *Pipeline) SInterStore(dest string, keys ...string) *IntResult {
+	result, cmd := sInterStoreCmd(dest, keys)
+	p.queue(cmd)
+	return result
+}
+
+// SUnionStore queues an SUnionStore.
+func (p *Pipeline) SUnionStore(dest string, keys ...string) *IntResult {
+	result, cmd := sUnionStoreCmd(dest, keys)
+	p.queue(cmd)
+	return result
+}
+
+// SDiffStore queues an SDiffStore.
+func (p *Pipeline) SDiffStore(dest string, keys ...string) *IntResult {
+	result, cmd := sDiffStoreCmd(dest, keys)
+	p.queue(cmd)
+	return result
+}
+
+// SUnion queues an SUnion.
+func (p *Pipeline) SUnion(keys ...string) *StringsResult {
+	result, cmd := sUnionCmd(keys)
+	p.queue(cmd)
+	return result
+}
+
+// SInter queues an SInter.
+func (p *Pipeline) SInter(keys ...string) *StringsResult {
+	result, cmd := sInterCmd(keys)
+	p.queue(cmd)
+	return result
+}
+
+// SDiff queues an SDiff.
+func (p *Pipeline) SDiff(keys ...string) *StringsResult {
+	result, cmd := sDiffCmd(keys)
+	p.queue(cmd)
+	return result
+}
+
+// SPop queues an SPop.
+func (p *Pipeline) SPop(key string, count int64) *StringsResult {
+	result, cmd := sPopCmd(key, count)
+	p.queue(cmd)
+	return result
+}
+
+// SRandMember queues an SRandMember.
+func (p *Pipeline) SRandMember(key string, count int64) *StringsResult {
+	result, cmd := sRandMemberCmd(key, count)
+	p.queue(cmd)
+	return result
+}
+
+// SMembers queues an SMembers.
+func (p *Pipeline) SMembers(key string) *StringsResult {
+	result, cmd := sMembersCmd(key)
+	p.queue(cmd)
+	return result
+}
+
+// SMove queues an SMove.
+func (p *Pipeline) SMove(src, dst, member string) *BoolResult {
+	result, cmd := sMoveCmd(src, dst, member)
+	p.queue(cmd)
+	return result
+}
+
+// SInterCard queues an SInterCard.
+func (p *Pipeline) SInterCard(limit int64, keys ...string) *IntResult {
+	result, cmd := sInterCardCmd(limit, keys)
+	p.queue(cmd)
+	return result
+}
+
+// ZRange queues a ZRange.
+func (p *Pipeline) ZRange(key string, start, stop int64) *StringsResult {
+	result, cmd := zRangeCmd(key, start, stop)
+	p.queue(cmd)
+	return result
+}
+
+// ZInterCard queues a ZInterCard.
+func (p *Pipeline) ZInterCard(limit int64, keys ...string) *IntResult {
+	result, cmd := zInterCardCmd(limit, keys)
+	p.queue(cmd)
+	return result
+}
+
+// ZRangeByLex queues a ZRangeByLex.
+func (p *Pipeline) ZRangeByLex(key, min, max string, opts LexRangeOptions) *StringsResult {
+	result, cmd := zRangeByLexCmd(key, min, max, opts)
+	p.queue(cmd)
+	return result
+}
@@ -0,0 +1,228 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPipeline_Exec(t *testing.T) {
+	t.Parallel()
+	t.Run("Sends every command at once and fills the results", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		response := append(append(asInteger(1), asSimpleErrorString("ERR value is not an integer or out of range")...), asBulkString("v")...)
+		conn, requests := recordingConn(t, response)
+		client.pool <- conn
+		p := client.Pipeline()
+		expire := p.Expire("a", time.Second)
+		incr := p.Incr("b")
+		getDel := p.GetDel("c")
+		if _, err := incr.Result(); !errors.Is(err, ErrNotExecuted) {
+			t.Errorf("Result() before Exec error = %v, wantErr %v", err, ErrNotExecuted)
+		}
+
+		err = p.Exec(context.Background())
+
+		if err == nil || err.Error() != "ERR value is not an integer or out of range" {
+			t.Errorf("Exec() error = %v, should be the INCR error", err)
+		}
+		want := string(command("EXPIRE", "a", "1")) + string(command("INCR", "b")) + string(command("GETDEL", "c"))
+		if got := <-requests; got != want {
+			t.Errorf("Exec() sent = %q, want %q", got, want)
+		}
+		if ok, err := expire.Result(); !ok || err != nil {
+			t.Errorf("Expire() got = %v, %v", ok, err)
+		}
+		var redisErr Error
+		if _, err := incr.Result(); !errors.As(err, &redisErr) || redisErr.Command != "INCR b" {
+			t.Errorf("Incr() error = %#v, want an Error from %q", err, "INCR b")
+		}
+		if v, exists, err := getDel.Result(); v != "v" || !exists || err != nil {
+			t.Errorf("GetDel() got = %v, %v, %v", v, exists, err)
+		}
+		if len(client.pool) != 1 {
+			t.Errorf("Should have put the conn back, pool has %v", len(client.pool))
+		}
+	})
+	t.Run("Commands settled without sending aren't queued", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		response := append(append(append([]byte{}, okString...), asBulkString("v")...), asArray(asBulkString("f"), asBulkString("1"))...)
+		conn, requests := recordingConn(t, response)
+		client.pool <- conn
+		p := client.Pipeline()
+		set := p.Set("a", "v")
+		hSetMulti := p.HSetMulti("b", nil)
+		get := p.Get("a")
+		mget := p.MGet()
+		hGetAll := p.HGetAll("c")
+
+		err = p.Exec(context.Background())
+
+		if err != nil {
+			t.Errorf("Exec() error = %v", err)
+		}
+		want := string(command("SET", "a", "v")) + string(command("GET", "a")) + string(command("HGETALL", "c"))
+		if got := <-requests; got != want {
+			t.Errorf("Exec() sent = %q, want %q", got, want)
+		}
+		if err := set.Result(); err != nil {
+			t.Errorf("Set() error = %v", err)
+		}
+		if _, err := hSetMulti.Result(); err == nil || errors.Is(err, ErrNotExecuted) {
+			t.Errorf("HSetMulti() error = %v, want the missing fields", err)
+		}
+		if v, exists, err := get.Result(); v != "v" || !exists || err != nil {
+			t.Errorf("Get() got = %v, %v, %v", v, exists, err)
+		}
+		if values, exists, err := mget.Result(); len(values) != 0 || len(exists) != 0 || err != nil {
+			t.Errorf("MGet() got = %v, %v, %v", values, exists, err)
+		}
+		if fields, err := hGetAll.Result(); !reflect.DeepEqual(fields, map[string]string{"f": "1"}) || err != nil {
+			t.Errorf("HGetAll() got = %v, %v", fields, err)
+		}
+	})
+	t.Run("I/O errors fail every unread result", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- brokenConn(t)
+		p := client.Pipeline()
+		incr := p.Incr("a")
+
+		err = p.Exec(context.Background())
+
+		if err == nil {
+			t.Errorf("Exec() error = %v, want an i/o error", err)
+		}
+		if _, gotErr := incr.Result(); gotErr != err {
+			t.Errorf("Incr() error = %v, want %v", gotErr, err)
+		}
+	})
+	t.Run("Discard drops queued commands without sending them", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, requests := recordingConn(t, asInteger(1))
+		client.pool <- conn
+		p := client.Pipeline()
+		discarded := p.Incr("a")
+
+		p.Discard()
+		incr := p.Incr("b")
+		err = p.Exec(context.Background())
+
+		if err != nil {
+			t.Errorf("Exec() error = %v", err)
+		}
+		if want := string(command("INCR", "b")); <-requests != want {
+			t.Errorf("Exec() should only have sent %q", want)
+		}
+		if _, err := discarded.Result(); !errors.Is(err, ErrNotExecuted) {
+			t.Errorf("Result() of a discarded command error = %v, wantErr %v", err, ErrNotExecuted)
+		}
+		if n, err := incr.Result(); n != 1 || err != nil {
+			t.Errorf("Incr() got = %v, %v", n, err)
+		}
+		if len(client.pool) != 1 {
+			t.Errorf("Should have put the conn back, pool has %v", len(client.pool))
+		}
+	})
+}
+
+// TestQueuedMethods guards against the Client, Pipeline and Multi command sets drifting apart.
+func TestQueuedMethods(t *testing.T) {
+	t.Parallel()
+	ignored := map[string]bool{"Exec": true, "Discard": true}
+	client := reflect.TypeOf(&Client{})
+	ctxType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	for _, queuer := range []reflect.Type{reflect.TypeOf(&Pipeline{}), reflect.TypeOf(&Multi{})} {
+		for i := 0; i < queuer.NumMethod(); i++ {
+			queued := queuer.Method(i)
+			if ignored[queued.Name] {
+				continue
+			}
+			direct, ok := client.MethodByName(queued.Name)
+			if !ok {
+				t.Errorf("%v.%v has no Client counterpart", queuer, queued.Name)
+				continue
+			}
+			// the Client method takes a leading ctx, past the receiver, that the queued one doesn't
+			if direct.Type.NumIn() != queued.Type.NumIn()+1 || direct.Type.In(1) != ctxType {
+				t.Errorf("%v.%v arguments don't match the Client method", queuer, queued.Name)
+				continue
+			}
+			for arg := 1; arg < queued.Type.NumIn(); arg++ {
+				if queued.Type.In(arg) != direct.Type.In(arg+1) {
+					t.Errorf("%v.%v argument %v doesn't match the Client method", queuer, queued.Name, arg)
+				}
+			}
+		}
+	}
+	pipeline, multi := reflect.TypeOf(&Pipeline{}), reflect.TypeOf(&Multi{})
+	for i := 0; i < pipeline.NumMethod(); i++ {
+		if _, ok := multi.MethodByName(pipeline.Method(i).Name); !ok {
+			t.Errorf("Multi is missing %v", pipeline.Method(i).Name)
+		}
+	}
+	for i := 0; i < multi.NumMethod(); i++ {
+		if _, ok := pipeline.MethodByName(multi.Method(i).Name); !ok {
+			t.Errorf("Pipeline is missing %v", multi.Method(i).Name)
+		}
+	}
+}
+
+// TestClientMethodsQueued guards against a new command only being added to the Client. Methods that can't be queued,
+// because they manage the Client, take several round trips, block or are about the server rather than the data, are
+// listed here instead.
+func TestClientMethodsQueued(t *testing.T) {
+	t.Parallel()
+	notQueued := map[string]bool{
+		// the Client itself
+		"Addr": true, "PoolSize": true, "Close": true, "CloseContext": true, "Conn": true, "Session": true,
+		"WithContext": true, "Pipeline": true, "Multi": true, "IOStats": true, "LastRTT": true,
+		"Stats": true, "Subscribe": true, "SetWriter": true, "Do": true, "DoArgs": true,
+		// several round trips, or decoding on the Client
+		"SetConfirm": true, "BulkSet": true, "GetOrSet": true, "GetAny": true, "GetScan": true, "GetObject": true,
+		"SetObject": true, "GetJSON": true, "SetJSON": true, "ExistsEach": true, "MGetSmart": true, "KeyInfo": true,
+		"BigKeys": true, "ObjectInfo": true, "Scan": true, "ScanFrom": true, "ScanAll": true, "ClusterScan": true,
+		"DeleteMatching": true,
+		// the popped key has the key prefix stripped by the Client, and BZMPop blocks
+		"ZMPop": true, "BZMPop": true,
+		// the server rather than the data
+		"ACLWhoAmI": true, "ACLGetUser": true, "ClusterSlots": true, "ConfigSet": true,
+		"SetListMaxListpackSize": true, "SetHashMaxListpackEntries": true, "SetHashMaxListpackValue": true,
+		"SetSetMaxIntsetEntries": true, "SetSetMaxListpackEntries": true, "SetZSetMaxListpackEntries": true,
+		"SetZSetMaxListpackValue": true, "DebugQuicklistPackedThreshold": true, "CommandCount": true,
+		"CommandList": true, "ClientNoEvict": true, "ServerInfo": true, "ReplicationOffset": true,
+		"LatencyLatest": true, "LatencyReset": true, "MemoryDoctor": true, "MemoryStats": true, "MemoryUsage": true,
+		"ObjectEncoding": true, "DebugObject": true, "BgSave": true, "BgRewriteAOF": true, "LastSave": true,
+		"Shutdown": true,
+	}
+	client := reflect.TypeOf(&Client{})
+	for _, queuer := range []reflect.Type{reflect.TypeOf(&Pipeline{}), reflect.TypeOf(&Multi{})} {
+		for i := 0; i < client.NumMethod(); i++ {
+			name := client.Method(i).Name
+			_, ok := queuer.MethodByName(name)
+			if !ok && !notQueued[name] {
+				t.Errorf("Client.%v has no %v counterpart", name, queuer)
+			}
+			if ok && notQueued[name] {
+				t.Errorf("Client.%v is queued by %v, but listed as not queued", name, queuer)
+			}
+		}
+	}
+}
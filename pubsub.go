@@ -0,0 +1,238 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultMessageBuffer is the number of messages a Subscription buffers unless WithMessageBuffer says otherwise.
+const DefaultMessageBuffer = 100
+
+// A Message is a message published to a channel the Subscription is subscribed to.
+type Message struct {
+	Channel string
+	Payload string
+}
+
+// A SubscribeOption configures a Subscription.
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	buffer      int
+	healthCheck time.Duration
+}
+
+// WithMessageBuffer sets how many messages are buffered for a slow consumer of Subscription.Messages.
+// Once the buffer is full, the Subscription stops reading from Redis until the consumer catches up.
+// Messages are never dropped by the client, though Redis itself disconnects subscribers that fall too far behind,
+// see client-output-buffer-limit.
+func WithMessageBuffer(n int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.buffer = n
+	}
+}
+
+// WithSubscribeHealthCheck sends PING on the Subscription's connection every interval, and ends the Subscription
+// with an error if nothing at all arrives within twice that while it is waiting to read. That catches half-open
+// connections, such as after a silent network failure, which would otherwise leave Messages quiet forever.
+// Time spent waiting on a slow consumer doesn't count. Subscribe again to recover.
+func WithSubscribeHealthCheck(interval time.Duration) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.healthCheck = interval
+	}
+}
+
+// A Subscription receives messages published to channels. It should be constructed with Subscribe and must be closed.
+type Subscription struct {
+	conn        net.Conn
+	stats       *ioStats
+	reader      *bufio.Reader
+	messages    chan Message
+	healthCheck time.Duration
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+// Subscribe subscribes to channels on a connection dedicated to the Subscription, and returns once Redis has
+// confirmed every subscription. Messages are then delivered in order on Messages.
+//
+// ctx bounds the whole Subscription, not just its setup: cancelling it ends the Subscription as if by Close, with
+// Err reporting ctx.Err(). Subscriptions that should outlive a request want a ctx that outlives it too.
+func (c *Client) Subscribe(ctx context.Context, channels []string, opts ...SubscribeOption) (*Subscription, error) {
+	o := subscribeOptions{buffer: DefaultMessageBuffer}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("redis: Subscribe requires at least one channel")
+	}
+	if c.pinned != nil {
+		return nil, fmt.Errorf("redis: Subscribe can't be called on a Conn")
+	}
+	if c.single != nil {
+		return nil, fmt.Errorf("redis: Subscribe can't be called on a Client made WithoutPool")
+	}
+	if err := c.checkAllowed([][]string{{"SUBSCRIBE"}}); err != nil {
+		return nil, err
+	}
+	if err := c.lifecycle.begin(); err != nil {
+		return nil, err
+	}
+	defer c.lifecycle.end()
+
+	conn, err := c.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscription{
+		conn:        conn,
+		stats:       c.stats,
+		reader:      bufio.NewReader(c.stats.reader(conn)),
+		messages:    make(chan Message, o.buffer),
+		healthCheck: o.healthCheck,
+		done:        make(chan struct{}),
+	}
+	if err := s.subscribe(channels); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	// the setup deadline must not apply to reading messages, which may be quiet for a long time
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	go s.receive()
+	if ctx.Done() != nil {
+		go s.watch(ctx)
+	}
+	if s.healthCheck > 0 {
+		go s.ping()
+	}
+	return s, nil
+}
+
+func (s *Subscription) subscribe(channels []string) error {
+	if err := s.stats.write(s.conn, command(append([]string{"SUBSCRIBE"}, channels...)...)); err != nil {
+		return err
+	}
+	for range channels {
+		reply, err := readReply(s.reader)
+		if err != nil {
+			return err
+		}
+		if kind, _ := pushKind(reply); kind != "subscribe" {
+			return fmt.Errorf("redis: expected a subscribe confirmation but got: %v", reply)
+		}
+	}
+	return nil
+}
+
+// Messages returns the channel messages are delivered on. It is closed once the Subscription ends, see Err.
+//
+// If the consumer falls behind, messages are buffered up to WithMessageBuffer. Past that the Subscription stops
+// reading from the connection, applying backpressure to Redis, rather than dropping messages.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Err returns the error that ended the Subscription, if any. It is only meaningful once Messages is closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Close ends the Subscription and closes its connection.
+func (s *Subscription) Close() error {
+	return s.end(nil)
+}
+
+// end ends the Subscription because of err, or on purpose if err is nil. Only the first call counts, so the errors
+// that closing the connection causes afterwards are just the fallout.
+func (s *Subscription) end(err error) error {
+	var closeErr error
+	s.closeOnce.Do(func() {
+		s.err = err
+		close(s.done)
+		closeErr = s.conn.Close()
+	})
+	return closeErr
+}
+
+// watch ends the Subscription once ctx is done.
+func (s *Subscription) watch(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		_ = s.end(ctx.Err())
+	case <-s.done:
+	}
+}
+
+func (s *Subscription) receive() {
+	defer close(s.messages)
+	for {
+		if s.healthCheck > 0 {
+			// a ping goes out within one interval, and its reply should be back within another
+			if err := s.conn.SetReadDeadline(time.Now().Add(2 * s.healthCheck)); err != nil {
+				_ = s.end(err)
+				return
+			}
+		}
+		reply, err := readReply(s.reader)
+		if err != nil {
+			if s.healthCheck > 0 && isTimeout(err) {
+				err = fmt.Errorf("redis: subscription health check failed, nothing received for %v: %w", 2*s.healthCheck, err)
+			}
+			_ = s.end(err)
+			return
+		}
+		kind, fields := pushKind(reply)
+		if kind != "message" || len(fields) != 3 {
+			continue
+		}
+		channel, _ := fields[1].(string)
+		payload, _ := fields[2].(string)
+		select {
+		case s.messages <- Message{Channel: channel, Payload: payload}:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// ping sends PING every healthCheck until the Subscription ends. Its replies are skipped by receive, which only
+// needs them to arrive. A failed write is left for receive to notice.
+func (s *Subscription) ping() {
+	ticker := time.NewTicker(s.healthCheck)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.stats.write(s.conn, command("PING")); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// pushKind returns the kind of a push reply, such as "message" or "subscribe", along with its fields.
+func pushKind(reply interface{}) (string, []interface{}) {
+	fields, ok := reply.([]interface{})
+	if !ok || len(fields) == 0 {
+		return "", nil
+	}
+	kind, _ := fields[0].(string)
+	return kind, fields
+}
@@ -0,0 +1,176 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func asMessage(channel, payload string) []byte {
+	return asArray(asBulkString("message"), asBulkString(channel), asBulkString(payload))
+}
+
+func subscribedPair(t *testing.T, opts ...SubscribeOption) (*Subscription, net.Conn) {
+	t.Helper()
+	return subscribedPairContext(t, context.Background(), opts...)
+}
+
+// subscribedPairContext is like subscribedPair, but the Subscription lasts only as long as ctx.
+func subscribedPairContext(t *testing.T, ctx context.Context, opts ...SubscribeOption) (*Subscription, net.Conn) {
+	t.Helper()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, serv := net.Pipe()
+	client.pool <- conn
+	go func() {
+		_, _ = serv.Read(make([]byte, 1024))
+		_, _ = serv.Write(asArray(asBulkString("subscribe"), asBulkString("news"), asInteger(1)))
+	}()
+	sub, err := client.Subscribe(ctx, []string{"news"}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = sub.Close()
+		_ = serv.Close()
+	})
+	return sub, serv
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	t.Parallel()
+	t.Run("Delivers messages in order", func(t *testing.T) {
+		t.Parallel()
+		sub, serv := subscribedPair(t)
+
+		_, _ = serv.Write(asMessage("news", "a"))
+		_, _ = serv.Write(asMessage("news", "b"))
+
+		for _, want := range []string{"a", "b"} {
+			if got := <-sub.Messages(); got.Payload != want || got.Channel != "news" {
+				t.Errorf("got %+v, want payload %v", got, want)
+			}
+		}
+	})
+	t.Run("A slow consumer applies backpressure instead of dropping messages", func(t *testing.T) {
+		t.Parallel()
+		sub, serv := subscribedPair(t, WithMessageBuffer(1))
+		written := make(chan string, 3)
+		go func() {
+			for _, payload := range []string{"a", "b", "c"} {
+				if _, err := serv.Write(asMessage("news", payload)); err != nil {
+					return
+				}
+				written <- payload
+			}
+		}()
+
+		// a is buffered, b is held by the reader, so writing c has to wait for the consumer
+		<-written
+		<-written
+		select {
+		case <-written:
+			t.Fatalf("Subscription kept reading past a full buffer")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		for _, want := range []string{"a", "b", "c"} {
+			if got := <-sub.Messages(); got.Payload != want {
+				t.Errorf("got %+v, want payload %v", got, want)
+			}
+		}
+	})
+	t.Run("Messages is closed when the connection dies", func(t *testing.T) {
+		t.Parallel()
+		sub, serv := subscribedPair(t)
+
+		_ = serv.Close()
+
+		if _, ok := <-sub.Messages(); ok {
+			t.Errorf("Messages() should have been closed")
+		}
+		if sub.Err() == nil {
+			t.Errorf("Err() should report why the Subscription ended")
+		}
+	})
+	t.Run("Health check keeps a responsive connection open", func(t *testing.T) {
+		t.Parallel()
+		sub, serv := subscribedPair(t, WithSubscribeHealthCheck(10*time.Millisecond))
+		go func() {
+			buf := make([]byte, 1024)
+			for {
+				if _, err := serv.Read(buf); err != nil {
+					return
+				}
+				if _, err := serv.Write(asArray(asBulkString("pong"), asBulkString(""))); err != nil {
+					return
+				}
+			}
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		_, _ = serv.Write(asMessage("news", "a"))
+
+		if got, ok := <-sub.Messages(); !ok || got.Payload != "a" {
+			t.Errorf("got %+v, %v, want payload a; Err() = %v", got, ok, sub.Err())
+		}
+	})
+	t.Run("Health check ends a Subscription whose pings go unanswered", func(t *testing.T) {
+		t.Parallel()
+		sub, serv := subscribedPair(t, WithSubscribeHealthCheck(10*time.Millisecond))
+		go func() {
+			buf := make([]byte, 1024)
+			for {
+				if _, err := serv.Read(buf); err != nil {
+					return
+				}
+			}
+		}()
+
+		select {
+		case _, ok := <-sub.Messages():
+			if ok {
+				t.Fatalf("unexpected message")
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Subscription should have ended")
+		}
+		if err := sub.Err(); err == nil || !strings.Contains(err.Error(), "health check") {
+			t.Errorf("Err() = %v, want a health check failure", err)
+		}
+	})
+}
+
+// TestClient_Subscribe_Cancel isn't parallel, so the goroutine count only moves because of this Subscription.
+func TestClient_Subscribe_Cancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, _ := subscribedPairContext(t, ctx, WithSubscribeHealthCheck(time.Hour))
+
+	cancel()
+
+	select {
+	case _, ok := <-sub.Messages():
+		if ok {
+			t.Fatalf("unexpected message")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Messages() should have been closed by cancelling ctx")
+	}
+	if err := sub.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Err() = %v, want %v", err, context.Canceled)
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("leaked goroutines: %v before Subscribe, %v after cancelling it", before, runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
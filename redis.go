@@ -3,91 +3,790 @@ package redis
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 const DefaultPoolSize = 10
 
+// DefaultDialGrace is how long a command waits for a connection in use to be returned before dialing a new one.
+const DefaultDialGrace = 2 * time.Millisecond
+
 var crlf = []byte("\r\n")
 
 // Error is a type used to distinguish between i/o errors and errors from Redis itself.
 // See https://redis.io/topics/protocol#resp-errors for more info
 type Error struct {
 	msg string
+	// Command is the command that got the error, such as "INCR counter", with arguments redacted as they are for
+	// hooks, see WithArgRedaction. It tells apart the commands of a Pipeline or Multi, and is empty for errors nested
+	// in a reply, such as in the array returned by Do.
+	Command string
 }
 
 func (e Error) Error() string {
 	return e.msg
 }
 
-// A Client represents a single connection to Redis. It should be constructed with New. It is not safe for concurrent access.
+// Code returns the error code, the first word of the message by convention, such as ERR or WRONGTYPE.
+func (e Error) Code() string {
+	code, _, _ := cut(e.msg, " ")
+	return code
+}
+
+// Is makes errors.Is(err, ErrWrongType) match WRONGTYPE errors.
+func (e Error) Is(target error) bool {
+	return target == ErrWrongType && e.Code() == "WRONGTYPE"
+}
+
+// ErrWrongType matches, via errors.Is, the WRONGTYPE Error Redis returns when a command is used against a key holding
+// another type, for example Get on a list. The Error itself is still available with errors.As.
+var ErrWrongType = errors.New("redis: operation against a key holding the wrong kind of value")
+
+// isRedisError reports whether err came from Redis itself, as opposed to i/o or parsing.
+func isRedisError(err error) bool {
+	var redisErr Error
+	return errors.As(err, &redisErr)
+}
+
+// isIOError reports whether err came from talking to Redis over the network, such as a failed dial, a timeout
+// or a connection closed mid reply. Errors from parsing a reply are not i/o errors.
+func isIOError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.ErrClosedPipe)
+}
+
+// isServerIOError is isIOError, except for the caller's context being canceled or running out, which is a net.Error
+// too but says nothing about Redis.
+func isServerIOError(err error) bool {
+	return isIOError(err) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// callerError returns the error of ctx once it is done, or past its deadline, which the deadline of a conn set from
+// it can notice first.
+func callerError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// A Client represents a pool of connections to Redis. It should be constructed with New.
+// It is safe for concurrent use by multiple goroutines, each command taking a connection of its own for its round trip.
 type Client struct {
 	dialer  net.Dialer
 	pool    chan net.Conn
 	address string
+
+	// sem holds a slot for every live connection, pooled or in use, so there are never more than maxConns
+	maxConns     int
+	sem          chan struct{}
+	dialGrace    time.Duration
+	drainOnError bool
+	// onConnect are commands replying +OK, run on every new connection
+	onConnect [][]string
+
+	replicaAddrs []string
+	replicas     *replicaSet
+	disallowed   map[string]bool
+	stats        *ioStats
+	serverInfo   *serverInfoCache
+	cluster      *clusterCache
+	auth         []string
+	// pinned is set on the view behind a Conn, which always uses the same connection
+	pinned *pinnedConn
+	// single is set by WithoutPool
+	single *singleConn
+
+	maxRetries int
+	backoff    BackoffFunc
+	idempotent map[string]bool
+	// connectRetries and connectBackoff are set by WithConnectRetry
+	connectRetries int
+	connectBackoff BackoffFunc
+
+	breaker   *circuitBreaker
+	hooks     []Hooks
+	redact    func(cmd string, argIndex int) bool
+	codec     Codec
+	flights   *flightGroup
+	lifecycle *lifecycle
+	metrics   *commandMetrics
+
+	// strictReplies is set by WithStrictReplies
+	strictReplies bool
+	maxReplySize  int64
+
+	// localCache is set by WithLocalCache
+	localCache *localCache
+	// keyPrefix is set by WithKeyPrefix
+	keyPrefix string
+	// tlsConfig is set by WithTLS
+	tlsConfig *tls.Config
+}
+
+// ErrClosed is returned by commands on a Client that has been closed.
+var ErrClosed = errors.New("redis: client is closed")
+
+// lifecycle tracks whether a Client is closed and which commands are still in flight.
+// It sits behind a pointer, so views made by WithContext share it.
+type lifecycle struct {
+	mu       sync.Mutex
+	closed   bool
+	inFlight sync.WaitGroup
+}
+
+// begin registers a command as in flight, unless the Client is closed. end must be called once it is done.
+func (l *lifecycle) begin() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	l.inFlight.Add(1)
+	return nil
 }
 
-// New creates a new Redis Client at the given address. It does not handle authentication at this time.
-func New(ctx context.Context, address string) (*Client, error) {
+func (l *lifecycle) end() {
+	l.inFlight.Done()
+}
+
+func (l *lifecycle) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+}
+
+// An Option configures a Client. Options are applied in order by New.
+type Option func(*Client)
+
+// WithDrainOnError closes every idle connection as soon as any command fails with an i/o error, so that following
+// commands dial afresh instead of each stale connection failing once on its own. This suits failovers, where a new
+// master makes all existing connections stale at once, at the cost of redialing after an isolated network blip.
+// A command cut short by its own ctx being canceled or running out doesn't count.
+func WithDrainOnError() Option {
+	return func(c *Client) {
+		c.drainOnError = true
+	}
+}
+
+// WithMaxConns caps the number of connections, idle or in use, the Client opens to Redis. It defaults to DefaultPoolSize.
+// Once the cap is reached, commands wait for a connection to be returned, or for their context to be done.
+func WithMaxConns(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxConns = n
+		}
+	}
+}
+
+// WithDialGrace sets how long a command waits for a connection in use to be returned to the pool before dialing a
+// new one, which saves opening connections under bursty load. It defaults to DefaultDialGrace, and 0 dials straight away.
+func WithDialGrace(d time.Duration) Option {
+	return func(c *Client) {
+		if d >= 0 {
+			c.dialGrace = d
+		}
+	}
+}
+
+// New creates a new Redis Client at the given address. Connections are opened as needed, except that New connects
+// straight away when WithAuth is used, so it can report ErrAuthFailed, or when WithConnectRetry is used.
+func New(ctx context.Context, address string, opts ...Option) (*Client, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
 	}
-	return &Client{
-		address: address,
-		pool:    make(chan net.Conn, DefaultPoolSize),
-	}, nil
+	c := &Client{
+		address:    address,
+		maxConns:   DefaultPoolSize,
+		dialGrace:  DefaultDialGrace,
+		lifecycle:  &lifecycle{},
+		stats:      &ioStats{},
+		serverInfo: &serverInfoCache{},
+		cluster:    &clusterCache{},
+		idempotent: defaultIdempotent(),
+		codec:      JSONCodec{},
+		flights:    &flightGroup{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.pool = make(chan net.Conn, c.maxConns)
+	c.sem = make(chan struct{}, c.maxConns)
+	if len(c.replicaAddrs) > 0 {
+		c.replicas = c.newReplicaSet()
+	}
+	if c.auth != nil || c.connectBackoff != nil {
+		conn, err := c.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.putConn(conn, nil)
+	}
+	return c, nil
+}
+
+// Addr returns the address the Client connects to, as given to New.
+func (c *Client) Addr() string {
+	return c.address
+}
+
+// PoolSize returns the most connections the Client opens to Redis, DefaultPoolSize unless WithMaxConns changed it.
+func (c *Client) PoolSize() int {
+	return c.maxConns
 }
 
-// Close closes all outstanding connections and prevents future operations on Client from succeeding
+// Close closes all idle connections and prevents future operations on Client from succeeding, they return ErrClosed.
+// Commands already in flight are allowed to finish, and their connections are closed once they do.
+// Use CloseContext to wait for them.
 func (c *Client) Close() error {
-	// for conn := range c.pool {
-	// 	conn.Close()
-	// }
-	// TODO figure out how to close channel safely
+	c.lifecycle.close()
+	c.closeIdle()
+	c.replicas.close()
+	c.cluster.close()
 	return nil
 }
 
-func (c *Client) getConn(ctx context.Context) (net.Conn, error) {
+// CloseContext is like Close, but waits for commands in flight to finish before returning.
+// If ctx is done first it returns ctx.Err(), and the remaining connections are closed as their commands finish.
+func (c *Client) CloseContext(ctx context.Context) error {
+	c.lifecycle.close()
+	c.closeIdle()
+	done := make(chan struct{})
+	go func() {
+		c.lifecycle.inFlight.Wait()
+		close(done)
+	}()
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
-	case conn := <-c.pool:
-		deadline, _ := ctx.Deadline()
-		if err := conn.SetDeadline(deadline); err != nil {
+		return ctx.Err()
+	case <-done:
+		c.closeIdle()
+		c.replicas.close()
+		c.cluster.close()
+		return nil
+	}
+}
+
+func (c *Client) closeIdle() {
+	if c.single != nil {
+		c.single.closeIdle()
+	}
+	for {
+		select {
+		case conn := <-c.pool:
 			_ = conn.Close()
-			// Not sure why SetDeadline can fail, but if it does discard the Conn
-			// and try again below
-		} else {
-			return conn, nil
+		default:
+			return
+		}
+	}
+}
+
+func (c *Client) getConn(ctx context.Context) (net.Conn, error) {
+	if c.pinned != nil {
+		return c.pinned.get(ctx)
+	}
+	if c.single != nil {
+		return c.getSingle(ctx)
+	}
+	for {
+		// prefer an idle connection whenever there is one
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case conn := <-c.pool:
+			if c.prepare(ctx, conn) {
+				return conn, nil
+			}
+			continue
+		default:
 		}
+
+		// connections in use may be about to come back, which is cheaper than dialing another
+		if c.dialGrace > 0 && len(c.sem) > len(c.pool) {
+			timer := time.NewTimer(c.dialGrace)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case conn := <-c.pool:
+				timer.Stop()
+				if c.prepare(ctx, conn) {
+					return conn, nil
+				}
+				continue
+			case <-timer.C:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case conn := <-c.pool:
+			if c.prepare(ctx, conn) {
+				return conn, nil
+			}
+		case c.sem <- struct{}{}:
+			return c.dial(ctx)
+		}
+	}
+}
+
+// prepare applies the ctx deadline to a pooled conn, discarding it if that fails.
+func (c *Client) prepare(ctx context.Context, conn net.Conn) bool {
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		// Not sure why SetDeadline can fail, but if it does discard the Conn and try another
+		_ = conn.Close()
+		return false
+	}
+	return true
+}
+
+// dial opens a new connection. The caller must hold a slot in c.sem, which is handed to the connection
+// and given back when it is closed.
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.address)
+	if err == nil && c.tlsConfig != nil {
+		conn, err = handshake(ctx, conn, c.tlsConfig, c.address)
+	}
+	if err != nil {
+		<-c.sem
+		return nil, err
+	}
+	conn = &countedConn{Conn: conn, release: func() { <-c.sem }}
+	// ctx may have ended just as the dial succeeded, and the caller has given up on this conn
+	if err := ctx.Err(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := c.setup(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// setup authenticates a new connection and runs the commands options asked for, before it is used.
+func (c *Client) setup(conn net.Conn) error {
+	cmds := c.onConnect
+	if c.auth != nil {
+		cmds = append([][]string{c.auth}, cmds...)
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	var payload []byte
+	for _, args := range cmds {
+		payload = append(payload, command(args...)...)
+	}
+	if err := c.stats.write(conn, payload); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(c.stats.reader(conn))
+	for _, args := range cmds {
+		if err := expectOK(reader); err != nil {
+			if authFailure(args, err) {
+				return authError{err: err}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// countedConn gives back its slot in Client.sem when closed, so the connection limit counts live connections.
+type countedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *countedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
+
+// putConn returns conn to the pool, unless err shows the connection can no longer be trusted.
+// Errors from Redis leave the connection in a known state, anything else (i/o, a reply we couldn't parse) does not.
+func (c *Client) putConn(conn net.Conn, err error) {
+	if c.pinned != nil {
+		c.pinned.put(err)
+		return
+	}
+	if c.single != nil {
+		c.putSingle(conn, err)
+		return
+	}
+	if c.drainOnError && isServerIOError(err) {
+		c.closeIdle()
+	}
+	if err != nil && !isRedisError(err) {
+		_ = conn.Close()
+		return
+	}
+	// hold the lock while pooling, so a concurrent Close either sees conn in the pool or has us close it
+	c.lifecycle.mu.Lock()
+	defer c.lifecycle.mu.Unlock()
+	if c.lifecycle.closed {
+		_ = conn.Close()
+		return
+	}
+	select {
+	case c.pool <- conn:
 	default:
+		// the pool can only fill up with conns that weren't dialed by this Client
+		_ = conn.Close()
 	}
-	return c.dialer.DialContext(ctx, "tcp", c.address)
 }
 
 // Set key to hold the string value.
 // If key already holds a value, it is overwritten, regardless of its type.
 // Any previous time to live associated with the key is discarded on successful SET operation.
 func (c *Client) Set(ctx context.Context, key string, value string) error {
-	conn, err := c.getConn(ctx)
-	if err != nil {
+	result, cmd := setCmd(key, value)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func setCmd(key, value string) (*OKResult, pendingCmd) {
+	result, cmd := newOKCmd("SET", key, value)
+	expectOK := cmd.read
+	cmd.read = func(reader *bufio.Reader) error {
+		msgType, err := reader.Peek(1)
+		if err != nil {
+			result.err = err
+			return err
+		}
+		if msgType[0] == '$' {
+			_, _, result.err = readBulkStringReply(reader)
+			return result.err
+		}
+		return expectOK(reader)
+	}
+	return result, cmd
+}
+
+// Get the value of the given key. If you wish to distinguish between a nil or empty string, check the exists bool.
+// If you solely wish to check for existence, you should use Exists instead.
+// An error is returned if the value stored at key is not a string, because GET only handles string values.
+func (c *Client) Get(ctx context.Context, key string) (value string, exists bool, err error) {
+	// Using named return values for documentation clarity, but I don't want to deal with it
+	// in the code because it's a messy feature. The real Get is implemented in get
+	if c.localCache != nil && c.pinned == nil {
+		return c.cachedGet(ctx, key)
+	}
+	return c.get(ctx, key)
+}
+
+func (c *Client) get(ctx context.Context, key string) (string, bool, error) {
+	result, cmd := getCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func getCmd(key string) (*StringResult, pendingCmd) {
+	return newStringCmd("GET", key)
+}
+
+// Do sends args to Redis as a single command and returns the decoded reply. It is an escape hatch for commands
+// that don't have a dedicated method yet. Arguments are sent verbatim, so they may contain any bytes.
+//
+// Simple and bulk strings are returned as string, integers as int64, arrays as []interface{} and nil replies as nil.
+// With RESP3, verbatim strings are returned as string without their format prefix, and big numbers as *big.Int.
+// An error reply is returned as the error. Error replies nested in an array are kept as elements of the array.
+//
+// Do can't tell which arguments are keys, so unlike the other methods it doesn't apply WithKeyPrefix.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	if len(args) == 0 {
+		// Redis silently waits for more input on an empty command, so it would never reply
+		return nil, errors.New("redis: Do requires at least a command name")
+	}
+	var reply interface{}
+	err := c.withCommand(c.execVerbatim(ctx, [][]string{args}, func(reader *bufio.Reader) error {
+		var err error
+		reply, err = readReply(reader)
+		return err
+	}), args)
+	return reply, err
+}
+
+// DoArgs is like Do, but accepts arguments of other types, which it formats the way Redis expects.
+// Supported types are string, []byte, bool (sent as 1 or 0), all integer types and float32/float64.
+// Any other type is an error and nothing is sent.
+func (c *Client) DoArgs(ctx context.Context, args ...interface{}) (interface{}, error) {
+	stringArgs := make([]string, len(args))
+	for i, arg := range args {
+		s, err := formatArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		stringArgs[i] = s
+	}
+	return c.Do(ctx, stringArgs...)
+}
+
+func formatArg(arg interface{}) (string, error) {
+	switch v := arg.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("redis: unsupported argument type %T", arg)
+	}
+}
+
+// exec checks out a connection, writes args as a single command and hands the reply to read.
+// It retries according to WithRetry.
+func (c *Client) exec(ctx context.Context, args []string, read func(reader *bufio.Reader) error) error {
+	return c.withCommand(c.execPipeline(ctx, [][]string{args}, read), args)
+}
+
+// withCommand sets the Command of err to args, if err is an Error that doesn't have one yet.
+func (c *Client) withCommand(err error, args []string) error {
+	redisErr, ok := err.(Error)
+	if !ok || redisErr.Command != "" {
+		return err
+	}
+	redisErr.Command = strings.Join(c.redactArgs([][]string{args})[0], " ")
+	return redisErr
+}
+
+// execPipeline is like exec, but writes several commands at once on the same connection.
+// read must consume every reply, even after an error reply, so the connection can be reused.
+func (c *Client) execPipeline(ctx context.Context, cmds [][]string, read func(reader *bufio.Reader) error) error {
+	return c.execVerbatim(ctx, c.prefixKeys(cmds), read)
+}
+
+// execVerbatim is like execPipeline, but sends cmds exactly as they are, without the key prefix.
+func (c *Client) execVerbatim(ctx context.Context, cmds [][]string, read func(reader *bufio.Reader) error) (err error) {
+	if err := c.checkAllowed(cmds); err != nil {
 		return err
 	}
+	if err := c.checkSupported(cmds); err != nil {
+		return err
+	}
+	if err := c.lifecycle.begin(); err != nil {
+		return err
+	}
+	defer c.lifecycle.end()
+	c.beforeCommand(ctx, cmds)
+	start := time.Now()
 	defer func() {
-		c.pool <- conn
+		elapsed := time.Since(start)
+		if c.metrics != nil {
+			c.metrics.record(cmds, elapsed, err)
+		}
+		c.afterCommand(ctx, cmds, elapsed, err)
 	}()
-	_, err = conn.Write(command(fmt.Sprintf("SET %s %s", key, value)))
+	if c.pinned != nil {
+		c.pinned.watch(cmds)
+	}
+	err = c.execOnce(ctx, cmds, read)
+	for attempt := 1; attempt <= c.maxRetries && c.shouldRetry(ctx, cmds, err); attempt++ {
+		if !sleep(ctx, c.backoff(attempt)) {
+			return err
+		}
+		err = c.execOnce(ctx, cmds, read)
+	}
+	return err
+}
+
+func (c *Client) execOnce(ctx context.Context, cmds [][]string, read func(reader *bufio.Reader) error) (err error) {
+	if c.breaker != nil {
+		if !c.breaker.allow() {
+			return ErrCircuitOpen
+		}
+		defer func() {
+			if ctxErr := callerError(ctx); ctxErr != nil && isIOError(err) {
+				// a conn deadline set from ctx fails as a plain i/o timeout, but it is still the caller giving up
+				c.breaker.record(ctxErr)
+				return
+			}
+			c.breaker.record(err)
+		}()
+	}
+	if c.replicas != nil && readOnly(cmds) {
+		if err := c.replicas.roundTrip(ctx, cmds, read); !isIOError(err) {
+			return err
+		}
+		// every replica is unreachable, so fall back to the primary
+	}
+	return c.roundTrip(ctx, cmds, read)
+}
+
+// roundTrip writes cmds on a connection from the pool and hands the replies to read.
+func (c *Client) roundTrip(ctx context.Context, cmds [][]string, read func(reader *bufio.Reader) error) error {
+	conn, err := c.getConn(ctx)
 	if err != nil {
 		return err
 	}
-	reader := bufio.NewReader(conn)
+	stop := func() {}
+	timeout, blocking := blockTimeout(cmds)
+	if blocking {
+		if stop, err = blockDeadline(ctx, conn, timeout); err != nil {
+			c.putConn(conn, err)
+			return err
+		}
+	}
+	var payload []byte
+	for _, args := range cmds {
+		payload = append(payload, command(args...)...)
+	}
+	start := time.Now()
+	reader := bufio.NewReader(c.limitReply(c.stats.reader(conn)))
+	err = c.send(conn, reader, payload, read)
+	if c.lostAuth(cmds, err) {
+		// the connection was logged out, such as by an ACL change, so log it back in and try once more
+		if err = c.reauth(conn, reader); err == nil {
+			err = c.send(conn, reader, payload, read)
+		}
+	}
+	if err == nil || isRedisError(err) {
+		c.stats.recordRTT(time.Since(start))
+	}
+	stop()
+	if ctxErr := callerError(ctx); ctxErr != nil && isIOError(err) {
+		// the conn ran into ctx rather than Redis failing, which putConn mustn't take out on the other conns
+		c.putConn(conn, fmt.Errorf("%w: %v", ctxErr, err))
+	} else {
+		c.putConn(conn, err)
+	}
+	if blocking && isIOError(err) && ctx.Err() != nil {
+		// the read was interrupted because of ctx, which explains it better than a timeout
+		return ctx.Err()
+	}
+	return err
+}
+
+// send writes payload on conn and hands the replies to read.
+func (c *Client) send(conn net.Conn, reader *bufio.Reader, payload []byte, read func(reader *bufio.Reader) error) error {
+	if err := c.stats.write(conn, payload); err != nil {
+		return err
+	}
+	// a Conn, or the connection of a Client made WithoutPool, may be in a transaction on purpose, but a pooled
+	// connection never should be
+	if c.pinned == nil && c.single == nil {
+		if err := expectNotQueued(reader); err != nil {
+			return err
+		}
+	}
+	return read(reader)
+}
+
+// ErrUnexpectedQueued is returned when a command from the pool gets QUEUED back, because the connection was left
+// inside a MULTI, for example by a Conn closed mid transaction. The connection is discarded.
+var ErrUnexpectedQueued = errors.New("redis: unexpected QUEUED reply outside a transaction")
+
+// expectNotQueued peeks at the first reply, returning ErrUnexpectedQueued if it is QUEUED. It never peeks past the
+// end of a shorter reply, which could block waiting for bytes that aren't coming.
+func expectNotQueued(reader *bufio.Reader) error {
+	const queued = "+QUEUED\r\n"
+	for n := 1; n <= len(queued); n++ {
+		peeked, err := reader.Peek(n)
+		if err != nil {
+			return err
+		}
+		if peeked[n-1] != queued[n-1] {
+			return nil
+		}
+	}
+	return ErrUnexpectedQueued
+}
+
+// execOK sends args and expects +OK back.
+func (c *Client) execOK(ctx context.Context, args ...string) error {
+	return c.exec(ctx, args, c.strictRead("+", expectOK))
+}
+
+// execSimpleString sends args and reads a simple string reply, such as a status message.
+func (c *Client) execSimpleString(ctx context.Context, args ...string) (string, error) {
+	var value string
+	err := c.exec(ctx, args, c.strictRead("+", func(reader *bufio.Reader) error {
+		var err error
+		value, err = readSimpleStringReply(reader)
+		return err
+	}))
+	return value, err
+}
+
+// execInteger sends args and reads an integer reply.
+func (c *Client) execInteger(ctx context.Context, args ...string) (int64, error) {
+	var n int64
+	err := c.exec(ctx, args, c.strictRead(":", func(reader *bufio.Reader) error {
+		var err error
+		n, err = readIntegerReply(reader)
+		return err
+	}))
+	return n, err
+}
+
+// execBulkString sends args and reads a bulk string reply.
+func (c *Client) execBulkString(ctx context.Context, args ...string) (string, bool, error) {
+	var value string
+	var exists bool
+	err := c.exec(ctx, args, c.strictRead("$", func(reader *bufio.Reader) error {
+		var err error
+		value, exists, err = readBulkStringReply(reader)
+		return err
+	}))
+	return value, exists, err
+}
+
+// expectOK reads a simple string reply, including its type, returning an error if it is anything but OK.
+func expectOK(reader *bufio.Reader) error {
 	msgType, err := reader.ReadByte()
 	if err != nil {
 		return err
@@ -97,43 +796,55 @@ func (c *Client) Set(ctx context.Context, key string, value string) error {
 	case '-':
 		return readErrorMessage(reader)
 	case '+':
-		ok, err := readSimpleString(reader)
-		if ok != "OK" {
-			return fmt.Errorf("redis: expected OK from Redis but got: %v", ok)
+		ok, err := readLineBytes(reader)
+		if err != nil {
+			return err
 		}
-		return err
-	case '$':
-		_, _, err := readBulkString(reader)
-		return err
+		if string(ok) != "OK" {
+			return fmt.Errorf("redis: expected OK from Redis but got: %s", ok)
+		}
+		return nil
 	default:
 		return fmt.Errorf("redis: unexpected message type %v", msgType)
 	}
 }
 
-// Get the value of the given key. If you wish to distinguish between a nil or empty string, check the exists bool.
-// If you solely wish to check for existence, you should use Exists instead.
-// An error is returned if the value stored at key is not a string, because GET only handles string values.
-func (c *Client) Get(ctx context.Context, key string) (value string, exists bool, err error) {
-	// Using named return values for documentation clarity, but I don't want to deal with it
-	// in the code because it's a messy feature. The real Get is implemented in get
-	return c.get(ctx, key)
+// readSimpleStringReply reads a simple string reply, including its type.
+func readSimpleStringReply(reader *bufio.Reader) (string, error) {
+	msgType, err := reader.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	switch msgType {
+	case '-':
+		return "", readErrorMessage(reader)
+	case '+':
+		return readSimpleString(reader)
+	default:
+		return "", fmt.Errorf("redis: unexpected message type %v", msgType)
+	}
 }
 
-func (c *Client) get(ctx context.Context, key string) (string, bool, error) {
-	conn, err := c.getConn(ctx)
+// readIntegerReply reads an integer reply, including its type.
+func readIntegerReply(reader *bufio.Reader) (int64, error) {
+	msgType, err := reader.ReadByte()
 	if err != nil {
-		return "", false, err
+		return 0, err
 	}
-	defer func() {
-		c.pool <- conn
-	}()
 
-	_, err = conn.Write(command("GET " + key))
-	if err != nil {
-		return "", false, err
+	switch msgType {
+	case '-':
+		return 0, readErrorMessage(reader)
+	case ':':
+		return readInteger(reader)
+	default:
+		return 0, fmt.Errorf("redis: unexpected message type %v", msgType)
 	}
+}
 
-	reader := bufio.NewReader(conn)
+// readBulkStringReply reads a bulk string reply, including its type.
+func readBulkStringReply(reader *bufio.Reader) (string, bool, error) {
 	msgType, err := reader.ReadByte()
 	if err != nil {
 		return "", false, err
@@ -144,38 +855,222 @@ func (c *Client) get(ctx context.Context, key string) (string, bool, error) {
 		return "", false, readErrorMessage(reader)
 	case '$':
 		return readBulkString(reader)
+	case '=':
+		return readVerbatimString(reader)
 	default:
 		return "", false, fmt.Errorf("redis: unexpected message type %v", msgType)
 	}
 }
 
+// readStringsReply reads an array reply of bulk strings, including its type. Nil elements become empty strings
+// and a nil array an empty slice.
+func readStringsReply(reader *bufio.Reader) ([]string, error) {
+	msgType, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if msgType == '-' {
+		return nil, readErrorMessage(reader)
+	}
+	if msgType != '*' {
+		return nil, fmt.Errorf("redis: unexpected message type %v", msgType)
+	}
+	size, err := readInteger(reader)
+	if err != nil {
+		return nil, err
+	}
+	if size < -1 {
+		return nil, fmt.Errorf("redis: invalid array length %v", size)
+	}
+	values := []string{}
+	for i := int64(0); i < size; i++ {
+		value, _, err := readBulkStringReply(reader)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// readReply reads a reply of any type. See Do for how each type is decoded.
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	msgType, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch msgType {
+	case '-':
+		return nil, readErrorMessage(reader)
+	case '+':
+		s, err := readSimpleString(reader)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case ':':
+		n, err := readInteger(reader)
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	case '$':
+		s, exists, err := readBulkString(reader)
+		if err != nil || !exists {
+			return nil, err
+		}
+		return s, nil
+	case '*':
+		array, err := readArray(reader)
+		if err != nil || array == nil {
+			return nil, err
+		}
+		return array, nil
+	case '=':
+		s, exists, err := readVerbatimString(reader)
+		if err != nil || !exists {
+			return nil, err
+		}
+		return s, nil
+	case '(':
+		line, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		n, ok := new(big.Int).SetString(line, 10)
+		if !ok {
+			return nil, fmt.Errorf("redis: invalid big number %q", line)
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected message type %v", msgType)
+	}
+}
+
 // either successfully reads the error message, returning an Error, or returns the i/o error
 func readErrorMessage(reader *bufio.Reader) error {
-	errMsg, err := reader.ReadString('\n')
+	errMsg, err := readLine(reader)
 	if err != nil {
 		return err
 	}
-	return errors.New(errMsg[0 : len(errMsg)-2])
+	return Error{msg: errMsg}
 }
 
 func readSimpleString(reader *bufio.Reader) (string, error) {
-	simpleString, err := reader.ReadString('\n')
+	return readLine(reader)
+}
+
+// readLine reads up to and including the next CRLF, returning the line without it.
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := readLineBytes(reader)
+	return string(line), err
+}
+
+// readLineBytes is like readLine, but for parsing internally it returns a slice into reader's buffer, which saves
+// allocating a string for every reply. The slice is only valid until the next read from reader.
+func readLineBytes(reader *bufio.Reader) ([]byte, error) {
+	line, err := reader.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		// longer than the buffer, which is rare enough to copy, before the next read overwrites it
+		line = append([]byte(nil), line...)
+		var rest []byte
+		rest, err = reader.ReadBytes('\n')
+		line = append(line, rest...)
+	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return simpleString[0 : len(simpleString)-2], nil
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, ProtocolError{Line: string(line)}
+	}
+	return line[0 : len(line)-2], nil
 }
 
-func readBulkString(reader *bufio.Reader) (string, bool, error) {
-	sizeS, err := reader.ReadString('\n')
+// readInteger reads the rest of an integer line. Redis integers are 64-bit, so it and everything built on it keep them
+// as int64 rather than int, which would truncate on 32-bit platforms.
+func readInteger(reader *bufio.Reader) (int64, error) {
+	line, err := readLineBytes(reader)
 	if err != nil {
-		return "", false, err
+		return 0, err
+	}
+	return parseInteger(line)
+}
+
+// parseInteger parses a decimal integer without converting line to a string, unless it is unusual enough to need
+// strconv, such as being out of range.
+func parseInteger(line []byte) (int64, error) {
+	digits := line
+	if len(digits) > 0 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	// 18 digits always fit in an int64
+	if len(digits) == 0 || len(digits) > 18 {
+		return strconv.ParseInt(string(line), 10, 64)
+	}
+	var n int64
+	for _, d := range digits {
+		if d < '0' || d > '9' {
+			return strconv.ParseInt(string(line), 10, 64)
+		}
+		n = n*10 + int64(d-'0')
+	}
+	if line[0] == '-' {
+		n = -n
+	}
+	return n, nil
+}
+
+func readArray(reader *bufio.Reader) ([]interface{}, error) {
+	size, err := readInteger(reader)
+	if err != nil {
+		return nil, err
+	}
+	if size == -1 {
+		return nil, nil
+	}
+	if size < -1 {
+		return nil, fmt.Errorf("redis: invalid array length %v", size)
+	}
+	// size comes from the server, so don't trust it enough to pre-allocate
+	array := []interface{}{}
+	for i := int64(0); i < size; i++ {
+		elem, err := readReply(reader)
+		var redisErr Error
+		if errors.As(err, &redisErr) {
+			// an error inside an array, such as one from EXEC, belongs to that element and not the whole reply
+			array = append(array, redisErr)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, elem)
 	}
-	sizeS = sizeS[0 : len(sizeS)-2] // drop crlf
-	size, err := strconv.Atoi(sizeS)
+	return array, nil
+}
+
+// readVerbatimString reads a RESP3 verbatim string, such as the reply of LOLWUT, which is a bulk string starting with
+// a three letter format and a colon, like "txt:". Only the text after the format is returned.
+func readVerbatimString(reader *bufio.Reader) (string, bool, error) {
+	s, exists, err := readBulkString(reader)
+	if err != nil || !exists {
+		return s, exists, err
+	}
+	if len(s) < 4 || s[3] != ':' {
+		return "", false, fmt.Errorf("redis: verbatim string without a format: %q", s)
+	}
+	return s[4:], true, nil
+}
+
+func readBulkString(reader *bufio.Reader) (string, bool, error) {
+	size, err := readInteger(reader)
 	if err != nil {
 		return "", false, err
 	}
+	if size < -1 {
+		return "", false, fmt.Errorf("redis: invalid bulk string length %v", size)
+	}
 	switch size {
 	case 0:
 		_, err := reader.Discard(2)
@@ -184,25 +1079,48 @@ func readBulkString(reader *bufio.Reader) (string, bool, error) {
 		}
 		return "", true, nil
 	case -1:
-		// no need to Discard, ReadString ate the CRLF
+		// no need to Discard, readInteger ate the CRLF
 		return "", false, err
-	default:
-		msg := make([]byte, size+2) // for crlf. Alternatively reader.Discard(2) but that introduces another err check
-		_, err = io.ReadFull(reader, msg)
+	}
+	if size > maxBulkPrealloc {
+		// size comes from the server, so past a point only allocate as the bytes actually arrive
+		var b strings.Builder
+		if _, err := io.CopyN(&b, reader, size); err != nil {
+			return "", false, err
+		}
+		if _, err := reader.Discard(2); err != nil {
+			return "", false, err
+		}
+		return b.String(), true, nil
+	}
+	if size+2 <= int64(reader.Size()) {
+		// small enough to read straight out of the buffer, so the string is the only allocation
+		msg, err := reader.Peek(int(size) + 2)
 		if err != nil {
 			return "", false, err
 		}
-		// discard crlf
-		return string(msg[0 : len(msg)-2]), true, nil
+		value := string(msg[:size])
+		if _, err := reader.Discard(int(size) + 2); err != nil {
+			return "", false, err
+		}
+		return value, true, nil
+	}
+	msg := make([]byte, size+2) // for crlf. Alternatively reader.Discard(2) but that introduces another err check
+	_, err = io.ReadFull(reader, msg)
+	if err != nil {
+		return "", false, err
 	}
+	// discard crlf
+	return string(msg[0 : len(msg)-2]), true, nil
 }
 
-func command(s string) []byte {
+// command encodes args as a RESP array of bulk strings. Arguments are never inspected or split,
+// so keys and values may safely contain spaces, CRLF or any other bytes.
+func command(args ...string) []byte {
 	var builder []byte
-	ss := strings.Split(s, " ")
-	builder = appendArrayToken(builder, len(ss))
-	for _, s := range ss {
-		builder = appendBulkString(builder, s)
+	builder = appendArrayToken(builder, len(args))
+	for _, arg := range args {
+		builder = appendBulkString(builder, arg)
 	}
 	return builder
 }
@@ -216,6 +1134,7 @@ func appendArrayToken(builder []byte, count int) []byte {
 
 func appendBulkString(builder []byte, s string) []byte {
 	builder = append(builder, '$')
+	// len of a string is its length in bytes, not runes, which is what the protocol expects
 	builder = append(builder, []byte(strconv.Itoa(len(s)))...)
 	builder = append(builder, crlf...)
 	builder = append(builder, s...)
@@ -1,13 +1,22 @@
 package redis
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"math/big"
 	"net"
 	"os"
+	"reflect"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 )
 
 var nullString = []byte("$-1\r\n")
@@ -36,6 +45,117 @@ func serverClientPair(t *testing.T) (*Client, chan []byte) {
 	return client, responseChan
 }
 
+// fakeConn returns a conn whose peer answers each command it reads with the next of responses.
+// Once responses run out the peer hangs up, so any further command fails instead of blocking.
+func fakeConn(t *testing.T, responses ...[]byte) net.Conn {
+	t.Helper()
+	conn, _ := recordingConn(t, responses...)
+	return conn
+}
+
+// recordingConn is like fakeConn, but also sends every command its peer reads on the returned channel
+func recordingConn(t *testing.T, responses ...[]byte) (net.Conn, <-chan string) {
+	t.Helper()
+	conn, serv := net.Pipe()
+	requests := make(chan string, len(responses))
+	go func() {
+		defer serv.Close()
+		// a request that never comes reads as "", rather than blocking the test
+		defer close(requests)
+		buf := make([]byte, 4096)
+		for _, response := range responses {
+			n, err := serv.Read(buf)
+			if err != nil {
+				return
+			}
+			requests <- string(buf[:n])
+			if _, err := serv.Write(response); err != nil {
+				return
+			}
+		}
+	}()
+	return conn, requests
+}
+
+// commandTest describes a single command method: the command it should send, and how it should decode response
+type commandTest struct {
+	name        string
+	call        func(ctx context.Context, c *Client) (interface{}, error)
+	response    []byte
+	wantCommand []string
+	want        interface{}
+	wantErr     bool
+}
+
+func runCommandTests(t *testing.T, tests []commandTest) {
+	t.Helper()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := New(context.Background(), "-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn, requests := recordingConn(t, tt.response)
+			client.pool <- conn
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			got, err := tt.call(ctx, client)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %#v, want %#v", got, tt.want)
+			}
+			if want := string(command(tt.wantCommand...)); len(requests) == 0 || <-requests != want {
+				t.Errorf("should have sent %q", want)
+			}
+		})
+	}
+}
+
+func asInteger(n int64) []byte {
+	return []byte(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func asArray(elems ...[]byte) []byte {
+	builder := append([]byte(nil), '*')
+	builder = append(builder, []byte(strconv.Itoa(len(elems)))...)
+	builder = append(builder, crlf...)
+	for _, elem := range elems {
+		builder = append(builder, elem...)
+	}
+	return builder
+}
+
+// brokenConn returns a conn that fails every read and write, as if Redis went away
+func brokenConn(t *testing.T) net.Conn {
+	t.Helper()
+	conn, serv := net.Pipe()
+	_ = serv.Close()
+	return failingConn{conn}
+}
+
+// failingConn is a conn that can still be checked out of the pool, as its deadline can be set, but fails all i/o
+type failingConn struct {
+	net.Conn
+}
+
+func (failingConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (failingConn) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func (failingConn) Read([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
 func asBulkString(s string) []byte {
 	builder := append([]byte(nil), '$')
 	builder = append(builder, []byte(strconv.Itoa(len(s)))...)
@@ -61,7 +181,7 @@ func asSimpleString(s string) []byte {
 
 func integrationClient(t *testing.T) *Client {
 	t.Helper()
-	if os.Getenv("INTEGRATION") != "" {
+	if os.Getenv("INTEGRATION") == "" {
 		t.Skip()
 	}
 	c, err := New(context.Background(), ":6379")
@@ -76,6 +196,12 @@ func integrationClient(t *testing.T) *Client {
 	})
 	return c
 }
+
+func bigNumber(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 10)
+	return n
+}
+
 func TestClient_Get(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -120,8 +246,16 @@ func TestClient_Get(t *testing.T) {
 			true,
 			nil,
 		},
+		{
+			"RESP3 verbatim strings",
+			[]byte("=7\r\ntxt:bar\r\n"),
+			"bar",
+			true,
+			nil,
+		},
 	}
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			client, responseChan := serverClientPair(t)
@@ -167,8 +301,14 @@ func TestClient_Set(t *testing.T) {
 			asSimpleErrorString("WRONGTYPE Operation against a key holding the wrong kind of value"),
 			errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"),
 		},
+		{
+			"Simple strings other than OK are errors",
+			asSimpleString("PONG"),
+			errors.New("redis: expected OK from Redis but got: PONG"),
+		},
 	}
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			client, responseChan := serverClientPair(t)
@@ -186,6 +326,298 @@ func TestClient_Set(t *testing.T) {
 	}
 }
 
+func TestError_Code(t *testing.T) {
+	t.Parallel()
+	for msg, want := range map[string]string{
+		"ERR unknown command":               "ERR",
+		"WRONGTYPE Operation against a key": "WRONGTYPE",
+		"NOAUTH":                            "NOAUTH",
+		"":                                  "",
+	} {
+		if got := (Error{msg: msg}).Code(); got != want {
+			t.Errorf("Code() of %q got = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestErrWrongType(t *testing.T) {
+	t.Parallel()
+	client, responseChan := serverClientPair(t)
+	responseChan <- asSimpleErrorString("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+	_, _, err := client.Get(context.Background(), "Foo")
+
+	if !errors.Is(err, ErrWrongType) {
+		t.Errorf("Get() error = %v, wantErr %v", err, ErrWrongType)
+	}
+	var redisErr Error
+	if !errors.As(err, &redisErr) || redisErr.Code() != "WRONGTYPE" {
+		t.Errorf("Get() error = %v, should still be the original Error", err)
+	}
+	if errors.Is(Error{msg: "ERR syntax error"}, ErrWrongType) {
+		t.Errorf("Other errors should not match ErrWrongType")
+	}
+}
+
+func TestError_Command(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1", WithArgRedaction(func(cmd string, argIndex int) bool {
+		return cmd == "SET" && argIndex == 2
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pool <- fakeConn(t, asSimpleErrorString("ERR syntax error"))
+
+	err = client.Set(context.Background(), "key", "secret")
+
+	var redisErr Error
+	if !errors.As(err, &redisErr) || redisErr.Command != "SET key ***" {
+		t.Errorf("Set() error = %#v, want the Command redacted to %q", err, "SET key ***")
+	}
+}
+
+func TestClient_Do(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		response []byte
+		want     interface{}
+		wantErr  error
+	}{
+		{
+			"Simple string",
+			okString,
+			"OK",
+			nil,
+		},
+		{
+			"Integer",
+			[]byte(":42\r\n"),
+			int64(42),
+			nil,
+		},
+		{
+			"Bulk string",
+			asBulkString("bar"),
+			"bar",
+			nil,
+		},
+		{
+			"Null bulk string",
+			nullString,
+			nil,
+			nil,
+		},
+		{
+			"Array",
+			[]byte("*3\r\n$3\r\nfoo\r\n:1\r\n$-1\r\n"),
+			[]interface{}{"foo", int64(1), nil},
+			nil,
+		},
+		{
+			"Errors nested in arrays are elements",
+			[]byte("*2\r\n+OK\r\n-ERR oops\r\n"),
+			[]interface{}{"OK", Error{msg: "ERR oops"}},
+			nil,
+		},
+		{
+			"Error messages are converted to errors",
+			asSimpleErrorString("ERR unknown command 'FOO'"),
+			nil,
+			errors.New("ERR unknown command 'FOO'"),
+		},
+		{
+			"Negative array lengths other than -1 are errors",
+			[]byte("*-2\r\n"),
+			nil,
+			errors.New("redis: invalid array length -2"),
+		},
+		{
+			"Negative bulk string lengths other than -1 are errors",
+			[]byte("$-3\r\n"),
+			nil,
+			errors.New("redis: invalid bulk string length -3"),
+		},
+		{
+			"Verbatim strings lose their format",
+			[]byte("=15\r\ntxt:Some string\r\n"),
+			"Some string",
+			nil,
+		},
+		{
+			"Verbatim strings without a format are errors",
+			[]byte("=4\r\ntext\r\n"),
+			nil,
+			errors.New(`redis: verbatim string without a format: "text"`),
+		},
+		{
+			"Big numbers",
+			[]byte("(3492890328409238509324850943850943825024385\r\n"),
+			bigNumber("3492890328409238509324850943850943825024385"),
+			nil,
+		},
+		{
+			"Invalid big numbers are errors",
+			[]byte("(12a\r\n"),
+			nil,
+			errors.New(`redis: invalid big number "12a"`),
+		},
+		{
+			"Simple strings longer than the read buffer",
+			asSimpleString(strings.Repeat("x", 5000)),
+			strings.Repeat("x", 5000),
+			nil,
+		},
+		{
+			"Integers out of range are errors",
+			[]byte(":99999999999999999999\r\n"),
+			nil,
+			errors.New(`strconv.ParseInt: parsing "99999999999999999999": value out of range`),
+		},
+		{
+			"Lines without CRLF are errors",
+			[]byte(":\n"),
+			nil,
+			ProtocolError{Line: "\n"},
+		},
+		{
+			"Simple strings cut short are errors",
+			[]byte("+\n"),
+			nil,
+			ProtocolError{Line: "\n"},
+		},
+		{
+			"Errors cut short are errors",
+			[]byte("-ERR\n"),
+			nil,
+			ProtocolError{Line: "ERR\n"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, responseChan := serverClientPair(t)
+			responseChan <- tt.response
+
+			got, err := client.Do(context.Background(), "FOO")
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && tt.wantErr.Error() != err.Error() {
+				t.Errorf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Do() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_DoArgs(t *testing.T) {
+	t.Parallel()
+	t.Run("Arguments are formatted", func(t *testing.T) {
+		t.Parallel()
+		conn, serv := net.Pipe()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- conn
+		requests := make(chan string, 1)
+		go func() {
+			buf := make([]byte, 2048)
+			n, _ := serv.Read(buf)
+			requests <- string(buf[:n])
+			_, _ = serv.Write(okString)
+		}()
+
+		_, err = client.DoArgs(context.Background(), "SET", []byte("Foo"), 42, int64(-1), uint8(7), 1.5, true)
+
+		if err != nil {
+			t.Errorf("DoArgs() error = %v", err)
+		}
+		want := string(command("SET", "Foo", "42", "-1", "7", "1.5", "1"))
+		if got := <-requests; got != want {
+			t.Errorf("DoArgs() sent = %q, want %q", got, want)
+		}
+	})
+	t.Run("Unsupported types are rejected before sending", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = client.DoArgs(context.Background(), "SET", struct{}{})
+
+		if err == nil {
+			t.Errorf("DoArgs() error = %v, want an error", err)
+		}
+	})
+}
+
+func TestClient_Do_NoArgs(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Do(context.Background())
+
+	if err == nil {
+		t.Errorf("Do() error = %v, want an error", err)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+	t.Run("Arguments are binary safe", func(t *testing.T) {
+		t.Parallel()
+		key := "a key\r\nwith\x00bytes"
+
+		got := command("GET", key)
+
+		want := "*2\r\n$3\r\nGET\r\n$17\r\n" + key + "\r\n"
+		if string(got) != want {
+			t.Errorf("command() got = %q, want %q", got, want)
+		}
+	})
+	t.Run("Lengths count bytes, not runes", func(t *testing.T) {
+		t.Parallel()
+		// 6 bytes in 2 runes, and 4 bytes in 1 rune
+		key, value := "キー", "😀"
+
+		got := command("SET", key, value)
+
+		want := "*3\r\n$3\r\nSET\r\n$6\r\n" + key + "\r\n$4\r\n" + value + "\r\n"
+		if string(got) != want {
+			t.Errorf("command() got = %q, want %q", got, want)
+		}
+	})
+	t.Run("Multibyte arguments of any type round trip", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, requests := recordingConn(t, asBulkString("値😀"))
+		client.pool <- conn
+
+		got, err := client.DoArgs(context.Background(), "ECHO", []byte("値😀"))
+
+		if err != nil || got != "値😀" {
+			t.Errorf("DoArgs() got = %q, %v, want %q", got, err, "値😀")
+		}
+		if want := string(command("ECHO", "値😀")); <-requests != want {
+			t.Errorf("DoArgs() should have sent %q", want)
+		}
+	})
+}
+
 func TestConcurrency(t *testing.T) {
 	t.Parallel()
 	t.Run("Should use two independent connections and put them back", func(t *testing.T) {
@@ -203,7 +635,7 @@ func TestConcurrency(t *testing.T) {
 		wg.Add(2)
 		f := func() {
 			defer wg.Done()
-			_, _, err = client.Get(context.Background(), "Foo")
+			_, _, err := client.Get(context.Background(), "Foo")
 			if err != nil {
 				t.Errorf("Got an error back from Get %v", err)
 			}
@@ -243,6 +675,370 @@ func TestConcurrency(t *testing.T) {
 	})
 }
 
+// echoServer listens on a local port and replies to every command with its last argument as a bulk string,
+// so each caller can check it got the reply to its own command.
+func echoServer(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					reply, err := readReply(reader)
+					if err != nil {
+						return
+					}
+					args, ok := reply.([]interface{})
+					if !ok || len(args) == 0 {
+						return
+					}
+					last, _ := args[len(args)-1].(string)
+					if _, err := conn.Write(asBulkString(last)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestConcurrency_Stress(t *testing.T) {
+	t.Parallel()
+	const maxConns, goroutines, commands = 3, 32, 50
+	client, err := New(context.Background(), echoServer(t), WithMaxConns(maxConns))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < commands; i++ {
+				key := "key:" + strconv.Itoa(g) + ":" + strconv.Itoa(i)
+				got, _, err := client.Get(context.Background(), key)
+				if err != nil || got != key {
+					t.Errorf("Get(%v) = %v, %v", key, got, err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if len(client.sem) > maxConns {
+		t.Errorf("opened %v connections, want at most %v", len(client.sem), maxConns)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.pool) != 0 {
+		t.Errorf("%v connections left idle after Close", len(client.pool))
+	}
+}
+
+func TestWithMaxConns(t *testing.T) {
+	t.Parallel()
+	t.Run("Waits instead of dialing past the cap", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithMaxConns(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// pretend the only allowed connection is in use
+		client.sem <- struct{}{}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, _, err = client.Get(ctx, "Foo")
+
+		// dialing "-1" would have failed with a different error
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Get() error = %v, wantErr %v", err, context.DeadlineExceeded)
+		}
+	})
+	t.Run("Picks up a returned connection while waiting", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithMaxConns(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.sem <- struct{}{}
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			client.pool <- fakeConn(t, asBulkString("bar"))
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		got, _, err := client.Get(ctx, "Foo")
+
+		if err != nil || got != "bar" {
+			t.Errorf("Get() got = %v, error = %v", got, err)
+		}
+	})
+	t.Run("Closing a dialed connection frees its slot", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithMaxConns(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.sem <- struct{}{}
+		conn := &countedConn{Conn: brokenConn(t), release: func() { <-client.sem }}
+
+		_ = conn.Close()
+		_ = conn.Close()
+
+		if len(client.sem) != 0 {
+			t.Errorf("Close() should have freed exactly one slot, %v in use", len(client.sem))
+		}
+	})
+}
+
+// cancelledAfterDial is a ctx whose Err is context.Canceled once dialed is set.
+type cancelledAfterDial struct {
+	context.Context
+	dialed int32
+}
+
+func (c *cancelledAfterDial) Err() error {
+	if atomic.LoadInt32(&c.dialed) == 1 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestClient_dial(t *testing.T) {
+	t.Parallel()
+	t.Run("A ctx cancelled as the dial completes discards the connection", func(t *testing.T) {
+		t.Parallel()
+		address, requests := listen(t, okString)
+		client, err := New(context.Background(), address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a real cancellation this late makes DialContext itself fail, so instead ctx only reports being cancelled
+		// once the socket exists, as if that happened the moment DialContext returned
+		ctx := &cancelledAfterDial{Context: context.Background()}
+		client.dialer.Control = func(network, address string, c syscall.RawConn) error {
+			atomic.StoreInt32(&ctx.dialed, 1)
+			return nil
+		}
+
+		_, err = client.Do(ctx, "PING")
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Do() error = %v, wantErr %v", err, context.Canceled)
+		}
+		if len(client.sem) != 0 || len(client.pool) != 0 {
+			t.Errorf("the connection should have been closed, %v in use, %v pooled", len(client.sem), len(client.pool))
+		}
+		select {
+		case req := <-requests:
+			t.Errorf("nothing should have been sent, got %q", req)
+		case <-time.After(20 * time.Millisecond):
+		}
+	})
+}
+
+func TestClient_AddrPoolSize(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "localhost:6379", WithMaxConns(0), WithMaxConns(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := client.Addr(); got != "localhost:6379" {
+		t.Errorf("Addr() = %v, want %v", got, "localhost:6379")
+	}
+	if got := client.PoolSize(); got != 3 {
+		t.Errorf("PoolSize() = %v, want %v", got, 3)
+	}
+
+	client, err = New(context.Background(), "localhost:6379")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := client.PoolSize(); got != DefaultPoolSize {
+		t.Errorf("PoolSize() = %v, want %v", got, DefaultPoolSize)
+	}
+}
+
+func TestWithDialGrace(t *testing.T) {
+	t.Parallel()
+	t.Run("Waits for a connection in use before dialing", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithMaxConns(2), WithDialGrace(time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// pretend one connection is in use, leaving room to dial another
+		client.sem <- struct{}{}
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			client.pool <- fakeConn(t, asBulkString("bar"))
+		}()
+
+		// dialing "-1" would have failed
+		got, _, err := client.Get(context.Background(), "Foo")
+
+		if err != nil || got != "bar" {
+			t.Errorf("Get() got = %v, error = %v", got, err)
+		}
+	})
+	t.Run("Dials once the grace window is over", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithMaxConns(2), WithDialGrace(time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.sem <- struct{}{}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, _, err = client.Get(ctx, "Foo")
+
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Get() error = %v, want a dial error", err)
+		}
+	})
+}
+
+func TestWithDrainOnError(t *testing.T) {
+	t.Parallel()
+	stuck := func(t *testing.T) net.Conn { return slowConn(t, time.Minute, okString) }
+	tests := []struct {
+		name     string
+		opts     []Option
+		first    func(t *testing.T) net.Conn
+		timeout  time.Duration
+		wantPool int
+	}{
+		{"Idle conns are closed after an i/o error", []Option{WithDrainOnError()}, brokenConn, time.Minute, 0},
+		{"Idle conns are kept by default", nil, brokenConn, time.Minute, 2},
+		{"Idle conns are kept when ctx runs out", []Option{WithDrainOnError()}, stuck, 20 * time.Millisecond, 2},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := New(context.Background(), "-1", tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.pool <- tt.first(t)
+			client.pool <- fakeConn(t, okString)
+			client.pool <- fakeConn(t, okString)
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			if _, _, err := client.Get(ctx, "Foo"); err == nil {
+				t.Errorf("Get() error = %v, want an i/o error", err)
+			}
+
+			if len(client.pool) != tt.wantPool {
+				t.Errorf("pool has %v conns, want %v", len(client.pool), tt.wantPool)
+			}
+		})
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, serv := net.Pipe()
+	client.pool <- conn
+
+	err = client.Close()
+
+	if err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, err := serv.Read(make([]byte, 1)); err == nil {
+		t.Errorf("Close() should have closed the idle conn")
+	}
+	if err := client.Set(context.Background(), "Foo", "bar"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Set() error = %v, wantErr %v", err, ErrClosed)
+	}
+}
+
+func TestClient_CloseContext(t *testing.T) {
+	t.Parallel()
+	// inFlight starts a Get whose reply is held back until the returned func is called
+	inFlight := func(t *testing.T, client *Client) (reply func(), done <-chan error) {
+		conn, serv := net.Pipe()
+		client.pool <- conn
+		errs := make(chan error, 1)
+		go func() {
+			_, _, err := client.Get(context.Background(), "Foo")
+			errs <- err
+		}()
+		if _, err := serv.Read(make([]byte, 1024)); err != nil {
+			t.Fatal(err)
+		}
+		return func() { _, _ = serv.Write(asBulkString("bar")) }, errs
+	}
+	t.Run("Waits for commands in flight", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply, getDone := inFlight(t, client)
+		closed := make(chan error, 1)
+		go func() {
+			closed <- client.CloseContext(context.Background())
+		}()
+
+		select {
+		case err := <-closed:
+			t.Fatalf("CloseContext() returned %v before the Get finished", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+		reply()
+
+		if err := <-getDone; err != nil {
+			t.Errorf("Get() error = %v", err)
+		}
+		if err := <-closed; err != nil {
+			t.Errorf("CloseContext() error = %v", err)
+		}
+		if len(client.pool) != 0 {
+			t.Errorf("Should have closed the returned conn, pool has %v", len(client.pool))
+		}
+	})
+	t.Run("Gives up when the context is done", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply, _ := inFlight(t, client)
+		defer reply()
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err = client.CloseContext(ctx)
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("CloseContext() error = %v, wantErr %v", err, context.DeadlineExceeded)
+		}
+	})
+}
+
 func Test_Integration(t *testing.T) {
 	c := integrationClient(t)
 	key := "X"
@@ -262,3 +1058,112 @@ func Test_Integration(t *testing.T) {
 		t.Errorf("Get() got = %v, want %v", got, want)
 	}
 }
+
+func Test_Integration_BinarySafeKeys(t *testing.T) {
+	c := integrationClient(t)
+	key := "binary key\r\nwith\x00NUL"
+	want := "value with spaces\r\nand\x00NUL"
+
+	err := c.Set(context.Background(), key, want)
+	if err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+
+	got, exists, err := c.Get(context.Background(), key)
+	if err != nil {
+		t.Errorf("Get() error = %v, wantErr %v", err, nil)
+		return
+	}
+	if !exists {
+		t.Errorf("Get() exists = %v, want %v", exists, true)
+	}
+	if got != want {
+		t.Errorf("Get() got = %q, want %q", got, want)
+	}
+}
+
+func TestParseInteger(t *testing.T) {
+	t.Parallel()
+	for _, line := range []string{"0", "42", "-1", "+7", "999999999999999999", "-9223372036854775808", "9223372036854775807"} {
+		want, _ := strconv.ParseInt(line, 10, 64)
+		if got, err := parseInteger([]byte(line)); err != nil || got != want {
+			t.Errorf("parseInteger(%q) got = %v, error = %v, want %v", line, got, err, want)
+		}
+	}
+	for _, line := range []string{"", "-", "1a", "9223372036854775808"} {
+		if _, err := parseInteger([]byte(line)); err == nil {
+			t.Errorf("parseInteger(%q) should fail", line)
+		}
+	}
+}
+
+// TestIntegerReplies_Beyond32Bits checks integers past 2^31 survive every path from the wire to the caller, so
+// nothing narrows them to int on 32-bit platforms.
+func TestIntegerReplies_Beyond32Bits(t *testing.T) {
+	t.Parallel()
+	const large = int64(1)<<40 + 7
+	runCommandTests(t, []commandTest{
+		{
+			name: "Do",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Do(ctx, "INCRBY", "k", strconv.FormatInt(large, 10))
+			},
+			response:    asInteger(large),
+			wantCommand: []string{"INCRBY", "k", strconv.FormatInt(large, 10)},
+			want:        large,
+		},
+		{
+			name: "Negative",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Do(ctx, "DECRBY", "k", strconv.FormatInt(large, 10))
+			},
+			response:    asInteger(-large),
+			wantCommand: []string{"DECRBY", "k", strconv.FormatInt(large, 10)},
+			want:        -large,
+		},
+		{
+			name: "Nested in an array",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Do(ctx, "EVAL", "return {ARGV[1]}", "0")
+			},
+			response:    asArray(asInteger(large)),
+			wantCommand: []string{"EVAL", "return {ARGV[1]}", "0"},
+			want:        []interface{}{large},
+		},
+		{
+			name: "Integer helper",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				size, _, err := c.MemoryUsage(ctx, "k")
+				return size, err
+			},
+			response:    asInteger(large),
+			wantCommand: []string{"MEMORY", "USAGE", "k"},
+			want:        large,
+		},
+	})
+}
+
+// BenchmarkReadReplies reads the replies of a GET heavy workload: bulk strings, the odd miss, and some integers.
+func BenchmarkReadReplies(b *testing.B) {
+	var replies []byte
+	for i := 0; i < 8; i++ {
+		replies = append(replies, asBulkString("some value")...)
+	}
+	replies = append(append(replies, nullString...), asInteger(42)...)
+	r := bytes.NewReader(replies)
+	reader := bufio.NewReader(r)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(replies)))
+	for i := 0; i < b.N; i++ {
+		r.Reset(replies)
+		reader.Reset(r)
+		for j := 0; j < 9; j++ {
+			if _, _, err := readBulkStringReply(reader); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if _, err := readIntegerReply(reader); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
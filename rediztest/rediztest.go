@@ -0,0 +1,35 @@
+// Package rediztest provides helpers for testing code that uses a redis.Client, either against a real server or
+// against Server, an in-memory fake.
+package rediztest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JeremyLoy/redis"
+)
+
+// timeout bounds each command the helpers send.
+const timeout = 5 * time.Second
+
+// AssertEncoding fails tb unless the value at key is stored in one of the encodings in want, as reported by OBJECT
+// ENCODING. It guards against collections outgrowing a compact encoding such as "listpack", which silently costs
+// memory. Encodings depend on the server version and its thresholds, see Client.SetHashMaxListpackEntries and
+// friends, so run it against the same Redis as production.
+func AssertEncoding(tb testing.TB, client *redis.Client, key string, want ...string) {
+	tb.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	got, err := client.ObjectEncoding(ctx, key)
+	if err != nil {
+		tb.Errorf("OBJECT ENCODING %v: %v", key, err)
+		return
+	}
+	for _, encoding := range want {
+		if got == encoding {
+			return
+		}
+	}
+	tb.Errorf("%v is encoded as %v, want one of %v", key, got, want)
+}
@@ -0,0 +1,82 @@
+package rediztest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/JeremyLoy/redis"
+)
+
+// serve answers every connection's first command with reply.
+func serve(t *testing.T, reply string) *redis.Client {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 4096)
+				if _, err := conn.Read(buf); err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte(reply))
+			}()
+		}
+	}()
+	client, err := redis.New(context.Background(), l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// recorder is a testing.TB that records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertEncoding(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		reply    string
+		want     []string
+		wantFail bool
+	}{
+		{"Matching encoding", "$8\r\nlistpack\r\n", []string{"listpack"}, false},
+		{"Any of several encodings", "$6\r\nintset\r\n", []string{"listpack", "intset"}, false},
+		{"Other encoding", "$9\r\nhashtable\r\n", []string{"listpack"}, true},
+		{"Missing key", "$-1\r\n", []string{"listpack"}, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := serve(t, tt.reply)
+			r := &recorder{}
+
+			AssertEncoding(r, client, "k", tt.want...)
+
+			if failed := len(r.errors) > 0; failed != tt.wantFail {
+				t.Errorf("AssertEncoding() failures = %v, want failure %v", r.errors, tt.wantFail)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package rediztest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// wrongType is the error Redis replies with when a command is used against a key holding another type.
+const wrongType = "WRONGTYPE Operation against a key holding the wrong kind of value"
+
+// A Server is an in-memory fake of Redis for unit tests that don't need a real one. It should be constructed with
+// NewServer.
+//
+// It understands a small set of commands: PING, FLUSHALL, DEL, EXISTS, TYPE, GET, SET, LPUSH, RPUSH, LLEN, LRANGE,
+// HSET, HGET, HGETALL, SADD, SCARD and SMEMBERS, without their options. It tracks the type of every key like Redis
+// does, so using a command against a key of another type, such as GET on a list, replies with a WRONGTYPE error.
+// Anything else replies with an unknown command error.
+type Server struct {
+	listener net.Listener
+
+	mu sync.Mutex
+	// values holds a string, []string for a list, map[string]string for a hash or map[string]struct{} for a set
+	values map[string]interface{}
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewServer starts a Server on a free local port, and stops it when tb's test ends.
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	s := &Server{
+		listener: l,
+		values:   make(map[string]interface{}),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.accept()
+	tb.Cleanup(s.close)
+	return s
+}
+
+// Addr returns the address to pass to redis.New.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *Server) close() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.serve(conn)
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write(s.do(args)); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command as the client sends it, an array of bulk strings.
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	n, err := readLength(reader, '*')
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		size, err := readLength(reader, '$')
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:size])
+	}
+	return args, nil
+}
+
+func readLength(reader *bufio.Reader, prefix byte) (int, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	if len(line) < 3 || line[0] != prefix {
+		return 0, fmt.Errorf("rediztest: unexpected line %q", line)
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(line[1:], "\r\n"))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("rediztest: invalid length in %q", line)
+	}
+	return n, nil
+}
+
+// A command is how the Server runs one command name.
+type command struct {
+	// minArgs and maxArgs count the command name too, and a maxArgs of -1 means no limit
+	minArgs int
+	maxArgs int
+	// typ is the type the key in args[1] must hold if it exists, or empty for commands that accept any
+	typ string
+	run func(s *Server, args []string) []byte
+}
+
+var commands = map[string]command{
+	"PING": {1, 2, "", func(s *Server, args []string) []byte {
+		if len(args) == 2 {
+			return bulkString(args[1])
+		}
+		return simpleString("PONG")
+	}},
+	"FLUSHALL": {1, 1, "", func(s *Server, args []string) []byte {
+		s.values = make(map[string]interface{})
+		return simpleString("OK")
+	}},
+	"DEL": {2, -1, "", func(s *Server, args []string) []byte {
+		var n int
+		for _, key := range args[1:] {
+			if _, ok := s.values[key]; ok {
+				delete(s.values, key)
+				n++
+			}
+		}
+		return integer(n)
+	}},
+	"EXISTS": {2, -1, "", func(s *Server, args []string) []byte {
+		var n int
+		for _, key := range args[1:] {
+			if _, ok := s.values[key]; ok {
+				n++
+			}
+		}
+		return integer(n)
+	}},
+	"TYPE": {2, 2, "", func(s *Server, args []string) []byte {
+		return simpleString(typeOf(s.values[args[1]]))
+	}},
+	"GET": {2, 2, "string", func(s *Server, args []string) []byte {
+		value, ok := s.values[args[1]].(string)
+		if !ok {
+			return []byte("$-1\r\n")
+		}
+		return bulkString(value)
+	}},
+	// SET replaces a value of any type, like Redis
+	"SET": {3, 3, "", func(s *Server, args []string) []byte {
+		s.values[args[1]] = args[2]
+		return simpleString("OK")
+	}},
+	"LPUSH": {3, -1, "list", func(s *Server, args []string) []byte {
+		list, _ := s.values[args[1]].([]string)
+		for _, value := range args[2:] {
+			list = append([]string{value}, list...)
+		}
+		s.values[args[1]] = list
+		return integer(len(list))
+	}},
+	"RPUSH": {3, -1, "list", func(s *Server, args []string) []byte {
+		list, _ := s.values[args[1]].([]string)
+		list = append(list, args[2:]...)
+		s.values[args[1]] = list
+		return integer(len(list))
+	}},
+	"LLEN": {2, 2, "list", func(s *Server, args []string) []byte {
+		list, _ := s.values[args[1]].([]string)
+		return integer(len(list))
+	}},
+	"LRANGE": {4, 4, "list", func(s *Server, args []string) []byte {
+		list, _ := s.values[args[1]].([]string)
+		start, err1 := strconv.Atoi(args[2])
+		stop, err2 := strconv.Atoi(args[3])
+		if err1 != nil || err2 != nil {
+			return simpleError("ERR value is not an integer or out of range")
+		}
+		if start < 0 {
+			start += len(list)
+		}
+		if stop < 0 {
+			stop += len(list)
+		}
+		if start < 0 {
+			start = 0
+		}
+		if stop >= len(list) {
+			stop = len(list) - 1
+		}
+		if start > stop {
+			return array(nil)
+		}
+		return array(list[start : stop+1])
+	}},
+	"HSET": {4, -1, "hash", func(s *Server, args []string) []byte {
+		if len(args)%2 != 0 {
+			return wrongArgs(args[0])
+		}
+		hash, ok := s.values[args[1]].(map[string]string)
+		if !ok {
+			hash = make(map[string]string)
+			s.values[args[1]] = hash
+		}
+		var added int
+		for i := 2; i < len(args); i += 2 {
+			if _, ok := hash[args[i]]; !ok {
+				added++
+			}
+			hash[args[i]] = args[i+1]
+		}
+		return integer(added)
+	}},
+	"HGET": {3, 3, "hash", func(s *Server, args []string) []byte {
+		hash, _ := s.values[args[1]].(map[string]string)
+		value, ok := hash[args[2]]
+		if !ok {
+			return []byte("$-1\r\n")
+		}
+		return bulkString(value)
+	}},
+	"HGETALL": {2, 2, "hash", func(s *Server, args []string) []byte {
+		hash, _ := s.values[args[1]].(map[string]string)
+		fields := make([]string, 0, len(hash))
+		for field := range hash {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		pairs := make([]string, 0, 2*len(hash))
+		for _, field := range fields {
+			pairs = append(pairs, field, hash[field])
+		}
+		return array(pairs)
+	}},
+	"SADD": {3, -1, "set", func(s *Server, args []string) []byte {
+		set, ok := s.values[args[1]].(map[string]struct{})
+		if !ok {
+			set = make(map[string]struct{})
+			s.values[args[1]] = set
+		}
+		var added int
+		for _, member := range args[2:] {
+			if _, ok := set[member]; !ok {
+				set[member] = struct{}{}
+				added++
+			}
+		}
+		return integer(added)
+	}},
+	"SCARD": {2, 2, "set", func(s *Server, args []string) []byte {
+		set, _ := s.values[args[1]].(map[string]struct{})
+		return integer(len(set))
+	}},
+	// SMEMBERS sorts the members, so tests don't depend on an order Redis doesn't promise anyway
+	"SMEMBERS": {2, 2, "set", func(s *Server, args []string) []byte {
+		set, _ := s.values[args[1]].(map[string]struct{})
+		members := make([]string, 0, len(set))
+		for member := range set {
+			members = append(members, member)
+		}
+		sort.Strings(members)
+		return array(members)
+	}},
+}
+
+// do runs args and returns the encoded reply.
+func (s *Server) do(args []string) []byte {
+	if len(args) == 0 {
+		return simpleError("ERR empty command")
+	}
+	cmd, ok := commands[strings.ToUpper(args[0])]
+	if !ok {
+		return simpleError(fmt.Sprintf("ERR unknown command '%v'", args[0]))
+	}
+	if len(args) < cmd.minArgs || (cmd.maxArgs >= 0 && len(args) > cmd.maxArgs) {
+		return wrongArgs(args[0])
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cmd.typ != "" {
+		if value, ok := s.values[args[1]]; ok && typeOf(value) != cmd.typ {
+			return simpleError(wrongType)
+		}
+	}
+	return cmd.run(s, args)
+}
+
+// typeOf returns the name TYPE replies with for value.
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case []string:
+		return "list"
+	case map[string]string:
+		return "hash"
+	case map[string]struct{}:
+		return "set"
+	default:
+		return "none"
+	}
+}
+
+func wrongArgs(name string) []byte {
+	return simpleError(fmt.Sprintf("ERR wrong number of arguments for '%v' command", strings.ToLower(name)))
+}
+
+func simpleString(s string) []byte {
+	return []byte("+" + s + "\r\n")
+}
+
+func simpleError(s string) []byte {
+	return []byte("-" + s + "\r\n")
+}
+
+func integer(n int) []byte {
+	return []byte(":" + strconv.Itoa(n) + "\r\n")
+}
+
+func bulkString(s string) []byte {
+	return []byte("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func array(elems []string) []byte {
+	b := []byte("*" + strconv.Itoa(len(elems)) + "\r\n")
+	for _, elem := range elems {
+		b = append(b, bulkString(elem)...)
+	}
+	return b
+}
@@ -0,0 +1,73 @@
+package rediztest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/JeremyLoy/redis"
+)
+
+func newClient(t *testing.T) *redis.Client {
+	t.Helper()
+	client, err := redis.New(context.Background(), NewServer(t).Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := newClient(t)
+
+	if err := client.Set(ctx, "s", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if got, exists, err := client.Get(ctx, "s"); got != "v" || !exists || err != nil {
+		t.Errorf("Get() got = %v, %v, %v", got, exists, err)
+	}
+	if _, err := client.Do(ctx, "RPUSH", "l", "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(ctx, "LPUSH", "l", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := client.LRange(ctx, "l", 0, -1); !reflect.DeepEqual(got, []string{"c", "a", "b"}) || err != nil {
+		t.Errorf("LRange() got = %v, %v", got, err)
+	}
+	if got, err := client.Type(ctx, "l"); got != "list" || err != nil {
+		t.Errorf("Type() got = %v, %v", got, err)
+	}
+	if got, err := client.Do(ctx, "FOO"); got != nil || err == nil {
+		t.Errorf("Do(FOO) got = %v, %v, want an unknown command error", got, err)
+	}
+}
+
+func TestServer_WrongType(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := newClient(t)
+	if _, err := client.Do(ctx, "LPUSH", "l", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := client.Get(ctx, "l")
+
+	if !errors.Is(err, redis.ErrWrongType) {
+		t.Errorf("Get() of a list error = %v, want %v", err, redis.ErrWrongType)
+	}
+	if _, err := client.Do(ctx, "SADD", "l", "m"); !errors.Is(err, redis.ErrWrongType) {
+		t.Errorf("SADD on a list error = %v, want %v", err, redis.ErrWrongType)
+	}
+	// SET overwrites whatever type was there, like Redis
+	if err := client.Set(ctx, "l", "v"); err != nil {
+		t.Errorf("Set() over a list error = %v", err)
+	}
+	if got, err := client.Type(ctx, "l"); got != "string" || err != nil {
+		t.Errorf("Type() after Set got = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+)
+
+// WithReadReplicas sends read only commands, such as GET, MGET or EXISTS, to the replicas at addrs in turn, and
+// everything else to the primary address given to New. Each replica gets its own pool of up to WithMaxConns
+// connections. If no replica can be reached, reads fall back to the primary.
+//
+// Replicas lag behind the primary, so a read straight after a write may not see it.
+// Commands sent together, such as in a Pipeline, only go to a replica if every one of them is read only,
+// and a Multi always goes to the primary.
+func WithReadReplicas(addrs []string) Option {
+	return func(c *Client) {
+		c.replicaAddrs = append([]string(nil), addrs...)
+	}
+}
+
+// readOnlyCommands are the commands that may be sent to a replica.
+var readOnlyCommands = map[string]bool{
+	"GET": true, "MGET": true, "EXISTS": true, "STRLEN": true, "GETRANGE": true, "GETBIT": true, "BITCOUNT": true,
+	"BITPOS": true, "TTL": true, "PTTL": true, "EXPIRETIME": true, "PEXPIRETIME": true, "TYPE": true,
+	"LRANGE": true, "LLEN": true, "LINDEX": true, "LPOS": true,
+	"HGET": true, "HMGET": true, "HGETALL": true, "HKEYS": true, "HVALS": true, "HLEN": true, "HEXISTS": true,
+	"HSTRLEN":  true,
+	"SMEMBERS": true, "SISMEMBER": true, "SMISMEMBER": true, "SCARD": true, "SINTER": true, "SUNION": true,
+	"SDIFF": true, "SINTERCARD": true,
+	"ZRANGE": true, "ZRANGEBYSCORE": true, "ZRANGEBYLEX": true, "ZSCORE": true, "ZMSCORE": true, "ZCARD": true,
+	"ZCOUNT": true, "ZLEXCOUNT": true, "ZRANK": true, "ZREVRANK": true, "ZINTERCARD": true,
+}
+
+func readOnly(cmds [][]string) bool {
+	for _, args := range cmds {
+		if len(args) == 0 || !readOnlyCommands[strings.ToUpper(args[0])] {
+			return false
+		}
+	}
+	return true
+}
+
+type replicaSet struct {
+	replicas []*Client
+	next     uint32
+}
+
+// newReplicaSet creates a Client per replica.
+func (c *Client) newReplicaSet() *replicaSet {
+	set := &replicaSet{}
+	for _, addr := range c.replicaAddrs {
+		set.replicas = append(set.replicas, c.newNodeClient(addr))
+	}
+	return set
+}
+
+// newNodeClient creates a Client for another node at addr, sharing c's connection settings but none of its other
+// options.
+func (c *Client) newNodeClient(addr string) *Client {
+	return &Client{
+		dialer:       c.dialer,
+		address:      addr,
+		maxConns:     c.maxConns,
+		dialGrace:    c.dialGrace,
+		pool:         make(chan net.Conn, c.maxConns),
+		sem:          make(chan struct{}, c.maxConns),
+		onConnect:    c.onConnect,
+		auth:         c.auth,
+		lifecycle:    &lifecycle{},
+		stats:        c.stats,
+		serverInfo:   &serverInfoCache{},
+		cluster:      &clusterCache{},
+		maxReplySize: c.maxReplySize,
+		keyPrefix:    c.keyPrefix,
+		tlsConfig:    c.tlsConfig,
+	}
+}
+
+// roundTrip sends cmds to the next replica, moving on to the following one if it can't be reached.
+func (s *replicaSet) roundTrip(ctx context.Context, cmds [][]string, read func(reader *bufio.Reader) error) error {
+	start := atomic.AddUint32(&s.next, 1)
+	var err error
+	for i := range s.replicas {
+		replica := s.replicas[(int(start)+i)%len(s.replicas)]
+		err = replica.roundTrip(ctx, cmds, read)
+		if !isIOError(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *replicaSet) close() {
+	if s == nil {
+		return
+	}
+	for _, replica := range s.replicas {
+		_ = replica.Close()
+	}
+}
@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithReadReplicas(t *testing.T) {
+	t.Parallel()
+	t.Run("Reads go round robin to replicas and writes to the primary", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithReadReplicas([]string{"-1", "-1"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.replicas.replicas[0].pool <- fakeConn(t, asBulkString("replica0"))
+		client.replicas.replicas[1].pool <- fakeConn(t, asBulkString("replica1"))
+		client.pool <- fakeConn(t, okString)
+
+		seen := make(map[string]bool)
+		for i := 0; i < 2; i++ {
+			got, _, err := client.Get(context.Background(), "Foo")
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+			seen[got] = true
+		}
+		if err := client.Set(context.Background(), "Foo", "bar"); err != nil {
+			t.Errorf("Set() error = %v", err)
+		}
+
+		if !seen["replica0"] || !seen["replica1"] {
+			t.Errorf("Get() should have used both replicas, got %v", seen)
+		}
+	})
+	t.Run("Reads fall back to the primary when replicas are unreachable", func(t *testing.T) {
+		t.Parallel()
+		// "-1" can't be dialed, so the replica is unreachable
+		client, err := New(context.Background(), "-1", WithReadReplicas([]string{"-1"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asBulkString("primary"))
+
+		got, _, err := client.Get(context.Background(), "Foo")
+
+		if err != nil || got != "primary" {
+			t.Errorf("Get() got = %v, error = %v", got, err)
+		}
+	})
+	t.Run("Redis errors from a replica are returned as is", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithReadReplicas([]string{"-1"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.replicas.replicas[0].pool <- fakeConn(t, asSimpleErrorString("WRONGTYPE Operation against a key holding the wrong kind of value"))
+		client.pool <- fakeConn(t, asBulkString("primary"))
+
+		_, _, err = client.Get(context.Background(), "Foo")
+
+		if err == nil {
+			t.Errorf("Get() error = %v, want the replica's error", err)
+		}
+		if len(client.pool) != 1 {
+			t.Errorf("Should not have used the primary")
+		}
+	})
+}
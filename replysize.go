@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"fmt"
+	"io"
+)
+
+// maxBulkPrealloc is the largest bulk string length allocated up front. Longer ones grow as they are read, so a
+// bogus length, or one over WithMaxReplySize, can't make us allocate it all at once.
+const maxBulkPrealloc = 1 << 20
+
+// ReplyTooLargeError is returned when the replies to a command exceed the limit set with WithMaxReplySize.
+// The connection is discarded, as the rest of the reply is left unread.
+type ReplyTooLargeError struct {
+	Limit int64
+}
+
+func (e ReplyTooLargeError) Error() string {
+	return fmt.Sprintf("redis: reply larger than the limit of %v bytes", e.Limit)
+}
+
+// WithMaxReplySize fails commands with a ReplyTooLargeError as soon as their reply goes over bytes, rather than
+// reading it all into memory, guarding against a runaway LRANGE key 0 -1 or a huge value. The replies of commands
+// sent together, such as in a Pipeline, count towards one limit. Zero, the default, means no limit.
+func WithMaxReplySize(bytes int64) Option {
+	return func(c *Client) {
+		if bytes >= 0 {
+			c.maxReplySize = bytes
+		}
+	}
+}
+
+// limitReply wraps r to fail once more than the maximum reply size has been read from it.
+func (c *Client) limitReply(r io.Reader) io.Reader {
+	if c.maxReplySize == 0 {
+		return r
+	}
+	return &limitedReader{r: r, remaining: c.maxReplySize, limit: c.maxReplySize}
+}
+
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		return 0, ReplyTooLargeError{Limit: l.limit}
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
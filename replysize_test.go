@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithMaxReplySize(t *testing.T) {
+	t.Parallel()
+	t.Run("Replies over the limit are an error", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithMaxReplySize(32))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asBulkString(strings.Repeat("x", 100)))
+
+		_, _, err = client.Get(context.Background(), "Foo")
+
+		var tooLarge ReplyTooLargeError
+		if !errors.As(err, &tooLarge) || tooLarge.Limit != 32 {
+			t.Errorf("Get() error = %v, want a ReplyTooLargeError", err)
+		}
+		if len(client.pool) != 0 {
+			t.Errorf("the connection should have been discarded")
+		}
+	})
+	t.Run("Replies within the limit are read", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithMaxReplySize(32))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asBulkString("bar"), asBulkString("baz"))
+
+		for i := 0; i < 2; i++ {
+			// the limit is per command, not for the life of the connection
+			if _, _, err := client.Get(context.Background(), "Foo"); err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+		}
+	})
+}
+
+func TestReadBulkString_Large(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := strings.Repeat("x", maxBulkPrealloc+10)
+	client.pool <- fakeConn(t, asBulkString(value), asBulkString("next"))
+
+	got, _, err := client.Get(context.Background(), "Foo")
+	if err != nil || got != value {
+		t.Errorf("Get() of a large value got %v bytes, error = %v", len(got), err)
+	}
+	got, _, err = client.Get(context.Background(), "Foo")
+	if err != nil || got != "next" {
+		t.Errorf("Get() after a large value got = %v, error = %v", got, err)
+	}
+}
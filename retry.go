@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// BackoffFunc returns how long to wait before the given retry attempt. Attempts start at 1.
+type BackoffFunc func(attempt int) time.Duration
+
+// ExponentialBackoff returns a BackoffFunc that waits base before the first retry and doubles the wait
+// on every following attempt, never waiting longer than max.
+func ExponentialBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			return max
+		}
+		return d
+	}
+}
+
+// WithRetry retries a command up to maxRetries times when it fails with an i/o error, waiting backoff between attempts.
+// Errors from Redis itself are never retried, as they are deterministic, and neither are replies that can't be parsed.
+// Retries stop early if waiting would outlive the context deadline.
+//
+// Only commands known to be idempotent are retried, as a command that failed mid-flight may still have been applied
+// by Redis. That covers read only commands plus SET without NX, XX or GET, MSET, SETEX and PSETEX. Anything else,
+// including any command sent through Do that isn't on that list, is not retried. Use WithIdempotent and
+// WithNonIdempotent to adjust the list.
+func WithRetry(maxRetries int, backoff BackoffFunc) Option {
+	if backoff == nil {
+		backoff = func(int) time.Duration { return 0 }
+	}
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// WithConnectRetry makes New connect straight away, and retry up to attempts times, waiting backoff between them,
+// while the connection fails with an i/o error or Redis replies LOADING. This rides out Redis starting up slightly
+// after the application, as is common with containers, instead of failing on the first command. Retries stop early
+// if waiting would outlive the context deadline given to New. Authentication failures are never retried.
+func WithConnectRetry(attempts int, backoff BackoffFunc) Option {
+	if backoff == nil {
+		backoff = func(int) time.Duration { return 0 }
+	}
+	return func(c *Client) {
+		c.connectRetries = attempts
+		c.connectBackoff = backoff
+	}
+}
+
+// connect opens the first connection for New, retrying according to WithConnectRetry.
+func (c *Client) connect(ctx context.Context) (net.Conn, error) {
+	for attempt := 1; ; attempt++ {
+		conn, err := c.getConn(ctx)
+		if err == nil {
+			return conn, nil
+		}
+		var redisErr Error
+		retryable := isIOError(err) || errors.As(err, &redisErr) && redisErr.Code() == "LOADING"
+		if !retryable || attempt > c.connectRetries || ctx.Err() != nil || !sleep(ctx, c.connectBackoff(attempt)) {
+			return nil, err
+		}
+	}
+}
+
+// WithIdempotent marks the named commands as safe to retry. Names are case-insensitive.
+func WithIdempotent(names ...string) Option {
+	return func(c *Client) {
+		for _, name := range names {
+			c.idempotent[strings.ToUpper(name)] = true
+		}
+	}
+}
+
+// WithNonIdempotent marks the named commands as unsafe to retry, even if they are retried by default. Names are case-insensitive.
+func WithNonIdempotent(names ...string) Option {
+	return func(c *Client) {
+		for _, name := range names {
+			delete(c.idempotent, strings.ToUpper(name))
+		}
+	}
+}
+
+func defaultIdempotent() map[string]bool {
+	m := make(map[string]bool)
+	for _, name := range []string{
+		"PING", "ECHO", "INFO", "TIME", "DBSIZE", "COMMAND",
+		"GET", "MGET", "GETRANGE", "STRLEN", "EXISTS", "TYPE", "TTL", "PTTL", "EXPIRETIME", "PEXPIRETIME",
+		"KEYS", "SCAN", "OBJECT", "MEMORY", "GETBIT", "BITCOUNT", "BITPOS", "PFCOUNT",
+		"LLEN", "LRANGE", "LINDEX", "LPOS",
+		"HGET", "HMGET", "HGETALL", "HKEYS", "HVALS", "HLEN", "HEXISTS", "HSTRLEN", "HSCAN",
+		"SMEMBERS", "SISMEMBER", "SMISMEMBER", "SCARD", "SINTER", "SUNION", "SDIFF", "SINTERCARD", "SSCAN",
+		"ZRANGE", "ZRANGEBYSCORE", "ZRANGEBYLEX", "ZREVRANGE", "ZSCORE", "ZMSCORE", "ZCARD", "ZCOUNT",
+		"ZLEXCOUNT", "ZRANK", "ZREVRANK", "ZINTERCARD", "ZSCAN",
+		"XRANGE", "XREVRANGE", "XLEN",
+		"SET", "MSET", "SETEX", "PSETEX",
+	} {
+		m[name] = true
+	}
+	return m
+}
+
+func (c *Client) shouldRetry(ctx context.Context, cmds [][]string, err error) bool {
+	if !isIOError(err) || ctx.Err() != nil {
+		return false
+	}
+	for _, args := range cmds {
+		if !c.isIdempotent(args) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Client) isIdempotent(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	name := strings.ToUpper(args[0])
+	if name == "SET" && len(args) > 3 {
+		for _, arg := range args[3:] {
+			switch strings.ToUpper(arg) {
+			case "NX", "XX", "GET":
+				// the outcome depends on state the lost attempt may have changed, e.g. a lock it acquired
+				return false
+			}
+		}
+	}
+	return c.idempotent[name]
+}
+
+// sleep waits for d, returning false without waiting if ctx would be done before then.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(d).After(deadline) {
+		return false
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,201 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Parallel()
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	for attempt, want := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond} {
+		if got := backoff(attempt + 1); got != want {
+			t.Errorf("backoff(%v) got = %v, want %v", attempt+1, got, want)
+		}
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Parallel()
+	t.Run("I/O errors are retried", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithRetry(1, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- brokenConn(t)
+		client.pool <- fakeConn(t, asBulkString("bar"))
+
+		got, _, err := client.Get(context.Background(), "Foo")
+
+		if err != nil {
+			t.Errorf("Get() error = %v", err)
+		}
+		if got != "bar" {
+			t.Errorf("Get() got = %v, want %v", got, "bar")
+		}
+	})
+	t.Run("Redis errors are not retried", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithRetry(1, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, asSimpleErrorString("ERR nope"))
+		client.pool <- fakeConn(t, okString)
+
+		err = client.Set(context.Background(), "Foo", "bar")
+
+		if err == nil || err.Error() != "ERR nope" {
+			t.Errorf("Set() error = %v, wantErr %v", err, "ERR nope")
+		}
+		if len(client.pool) != 2 {
+			t.Errorf("Should not have used the second conn, pool has %v", len(client.pool))
+		}
+	})
+	notRetried := []struct {
+		name    string
+		options []Option
+		args    []string
+	}{
+		{"Commands not known to be idempotent are not retried", nil, []string{"INCR", "Foo"}},
+		{"SET NX is not retried", nil, []string{"SET", "Foo", "bar", "NX"}},
+		{"WithNonIdempotent opts commands out", []Option{WithNonIdempotent("get")}, []string{"GET", "Foo"}},
+	}
+	for _, tt := range notRetried {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := New(context.Background(), "-1", append([]Option{WithRetry(1, nil)}, tt.options...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.pool <- brokenConn(t)
+			client.pool <- fakeConn(t, okString)
+
+			_, err = client.Do(context.Background(), tt.args...)
+
+			if err == nil {
+				t.Errorf("Do() error = %v, want an i/o error", err)
+			}
+			if len(client.pool) != 1 {
+				t.Errorf("Should have discarded only the broken conn, pool has %v", len(client.pool))
+			}
+		})
+	}
+	t.Run("WithIdempotent opts commands in", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithRetry(1, nil), WithIdempotent("frob"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- brokenConn(t)
+		client.pool <- fakeConn(t, okString)
+
+		_, err = client.Do(context.Background(), "FROB", "Foo")
+
+		if err != nil {
+			t.Errorf("Do() error = %v", err)
+		}
+	})
+	t.Run("Unparseable replies are not retried", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithRetry(1, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, []byte("?\r\n"))
+		client.pool <- fakeConn(t, asBulkString("bar"))
+
+		_, _, err = client.Get(context.Background(), "Foo")
+
+		if err == nil {
+			t.Errorf("Get() error = %v, want a parse error", err)
+		}
+		if len(client.pool) != 1 {
+			t.Errorf("Should not have used the second conn, pool has %v", len(client.pool))
+		}
+	})
+	t.Run("Retries stop at the context deadline", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithRetry(1, ExponentialBackoff(time.Hour, time.Hour)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- brokenConn(t)
+		client.pool <- fakeConn(t, okString)
+		timeout := time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		start := time.Now()
+
+		err = client.Set(ctx, "Foo", "bar")
+
+		if err == nil {
+			t.Errorf("Set() error = %v, want an i/o error", err)
+		}
+		if elapsed := time.Since(start); elapsed > timeout/2 {
+			t.Errorf("Set() took %v, should have given up without waiting for the deadline", elapsed)
+		}
+		if len(client.pool) != 1 {
+			t.Errorf("Should not have used the second conn, pool has %v", len(client.pool))
+		}
+	})
+}
+
+func TestWithConnectRetry(t *testing.T) {
+	t.Parallel()
+	t.Run("Waits for Redis to start listening", func(t *testing.T) {
+		t.Parallel()
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		address := l.Addr().String()
+		_ = l.Close()
+		var attempts int
+		backoff := func(attempt int) time.Duration {
+			attempts = attempt
+			if attempt == 2 {
+				// start "Redis" during the second wait
+				l, err = net.Listen("tcp", address)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { _ = l.Close() })
+			}
+			return time.Millisecond
+		}
+
+		client, err := New(context.Background(), address, WithConnectRetry(5, backoff))
+
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if attempts != 2 {
+			t.Errorf("New() retried %v times, want %v", attempts, 2)
+		}
+		if len(client.pool) != 1 {
+			t.Errorf("New() should have pooled its connection, pool has %v", len(client.pool))
+		}
+	})
+	t.Run("Gives up after the last attempt", func(t *testing.T) {
+		t.Parallel()
+		var attempts int
+		backoff := func(attempt int) time.Duration {
+			attempts = attempt
+			return 0
+		}
+
+		_, err := New(context.Background(), "-1", WithConnectRetry(3, backoff))
+
+		if err == nil {
+			t.Errorf("New() should have failed to connect")
+		}
+		if attempts != 3 {
+			t.Errorf("New() retried %v times, want %v", attempts, 3)
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// ScanOptions filters the keys returned by Scan.
+type ScanOptions struct {
+	// Match only returns keys matching the glob-style pattern. Empty means all keys.
+	Match string
+	// Count hints how many keys Redis should look at per round trip. Zero uses the server default.
+	Count int64
+	// Type only returns keys holding this type, such as "string" or "zset". Empty means any type.
+	Type string
+}
+
+// A ScanIterator walks the keyspace with SCAN, a batch at a time. It should be constructed with Scan or ClusterScan.
+// Like SCAN itself, it may return a key more than once, and keys added or removed during the iteration may or may not be returned.
+//
+//	it := client.Scan(ctx, redis.ScanOptions{Match: "user:*"})
+//	for it.Next() {
+//		fmt.Println(it.Key())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ScanIterator struct {
+	client *Client
+	ctx    context.Context
+	opts   ScanOptions
+
+	cursor string
+	// from is the cursor the buffered keys were fetched with, empty for the first batch
+	from string
+	keys []string
+	key  string
+	err  error
+
+	// cluster is the Client a ClusterScan looks up the masters from, and nil for a plain Scan
+	cluster *Client
+	// masters are the addresses of the masters a ClusterScan has yet to visit, nil until they are looked up
+	masters []string
+}
+
+// Scan returns an iterator over the keys matching opts. No command is sent until the first call to Next.
+func (c *Client) Scan(ctx context.Context, opts ScanOptions) *ScanIterator {
+	return &ScanIterator{client: c, ctx: ctx, opts: opts}
+}
+
+// ScanFrom is like Scan, but resumes an iteration from a cursor saved with ScanIterator.Cursor.
+// The opts should match those of the original Scan. A cursor of "0" is an iteration that already completed, so Next
+// returns false straight away, while an empty cursor starts a new one.
+func (c *Client) ScanFrom(ctx context.Context, cursor string, opts ScanOptions) *ScanIterator {
+	return &ScanIterator{client: c, ctx: ctx, opts: opts, cursor: cursor}
+}
+
+// ClusterScan is like Scan, but walks the keyspace of a whole cluster, rather than the one node c is connected to,
+// by scanning each master in turn. The masters are looked up with ClusterSlots on the first call to Next, and each
+// is scanned on its own connection, closed once done with. Drive the iterator until Next returns false, or the
+// connections to the master being scanned stay open.
+//
+// Cursor only applies to the master being scanned, so a ClusterScan can't be resumed with ScanFrom.
+func (c *Client) ClusterScan(ctx context.Context, opts ScanOptions) *ScanIterator {
+	// a finished cursor makes the first Next move on to the first master
+	return &ScanIterator{client: c, ctx: ctx, opts: opts, cursor: "0", cluster: c}
+}
+
+// ScanAll drives a Scan to completion and returns every matching key. As it holds the whole result in memory,
+// it is meant for tooling and tests against small databases. Prefer Scan in production code.
+func (c *Client) ScanAll(ctx context.Context, opts ScanOptions) ([]string, error) {
+	keys := []string{}
+	it := c.Scan(ctx, opts)
+	for it.Next() {
+		keys = append(keys, it.Key())
+	}
+	return keys, it.Err()
+}
+
+// DeleteMatching deletes every key matching the glob-style pattern and returns how many were deleted.
+// It scans for keys batchSize at a time and removes each batch with UNLINK, which frees memory in the background,
+// so unlike KEYS followed by DEL it never blocks Redis for long. Keys created while it runs may be missed.
+func (c *Client) DeleteMatching(ctx context.Context, pattern string, batchSize int) (int64, error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("redis: DeleteMatching batchSize must be positive, got %v", batchSize)
+	}
+	var deleted int64
+	unlink := func(keys []string) error {
+		n, err := c.execInteger(ctx, append([]string{"UNLINK"}, keys...)...)
+		deleted += n
+		return err
+	}
+	batch := make([]string, 0, batchSize)
+	it := c.Scan(ctx, ScanOptions{Match: pattern, Count: int64(batchSize)})
+	for it.Next() {
+		batch = append(batch, it.Key())
+		if len(batch) < batchSize {
+			continue
+		}
+		if err := unlink(batch); err != nil {
+			return deleted, err
+		}
+		batch = batch[:0]
+	}
+	if err := it.Err(); err != nil {
+		return deleted, err
+	}
+	if len(batch) > 0 {
+		if err := unlink(batch); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// Next advances to the next key, fetching another batch from Redis when needed.
+// It returns false when the iteration is complete or an error occurred, see Err.
+func (it *ScanIterator) Next() bool {
+	for len(it.keys) == 0 {
+		if it.err != nil {
+			it.closeNode()
+			return false
+		}
+		if it.cursor == "0" && !it.nextNode() {
+			return false
+		}
+		it.from = it.cursor
+		cursor := it.cursor
+		if cursor == "" {
+			cursor = "0"
+		}
+		it.cursor, it.keys, it.err = it.client.scan(it.ctx, cursor, it.opts)
+	}
+	it.key, it.keys = it.keys[0], it.keys[1:]
+	return true
+}
+
+// nextNode moves a ClusterScan on to the next master, returning false once there are none left, or for a plain Scan.
+func (it *ScanIterator) nextNode() bool {
+	if it.cluster == nil {
+		return false
+	}
+	it.closeNode()
+	if it.masters == nil {
+		slots, err := it.cluster.ClusterSlots(it.ctx)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.masters = []string{}
+		seen := make(map[string]bool)
+		for _, slotRange := range slots {
+			addr := slotRange.Master.String()
+			if !seen[addr] {
+				seen[addr] = true
+				it.masters = append(it.masters, addr)
+			}
+		}
+	}
+	if len(it.masters) == 0 {
+		return false
+	}
+	it.client = it.cluster.newNodeClient(it.masters[0])
+	it.masters = it.masters[1:]
+	it.cursor = ""
+	return true
+}
+
+// closeNode closes the Client of the master a ClusterScan is on.
+func (it *ScanIterator) closeNode() {
+	if it.cluster != nil && it.client != it.cluster {
+		_ = it.client.Close()
+		it.client = it.cluster
+	}
+}
+
+// Key returns the key Next advanced to.
+func (it *ScanIterator) Key() string {
+	return it.key
+}
+
+// Cursor returns a cursor to save, so a later ScanFrom can carry on after the keys Next has returned so far.
+// While a batch is part way through, it is the cursor of that batch, so resuming may return some keys again.
+// It is "0" once the iteration is complete, and empty until the first batch is done with.
+func (it *ScanIterator) Cursor() string {
+	if len(it.keys) > 0 {
+		return it.from
+	}
+	return it.cursor
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ScanIterator) Err() error {
+	return it.err
+}
+
+func (c *Client) scan(ctx context.Context, cursor string, opts ScanOptions) (string, []string, error) {
+	args := []string{"SCAN", cursor}
+	if c.keyPrefix != "" {
+		args = append(args, "MATCH", c.matchUnderPrefix(opts.Match))
+	} else if opts.Match != "" {
+		args = append(args, "MATCH", opts.Match)
+	}
+	if opts.Count > 0 {
+		args = append(args, "COUNT", strconv.FormatInt(opts.Count, 10))
+	}
+	if opts.Type != "" {
+		args = append(args, "TYPE", opts.Type)
+	}
+	var next string
+	var keys []string
+	err := c.exec(ctx, args, func(reader *bufio.Reader) error {
+		var err error
+		next, keys, err = readScanReply(reader)
+		for i := range keys {
+			keys[i] = c.stripKeyPrefix(keys[i])
+		}
+		return err
+	})
+	return next, keys, err
+}
+
+// readScanReply reads the two element array of the next cursor and a batch of results shared by the SCAN family.
+func readScanReply(reader *bufio.Reader) (string, []string, error) {
+	msgType, err := reader.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+	if msgType == '-' {
+		return "", nil, readErrorMessage(reader)
+	}
+	if msgType != '*' {
+		return "", nil, fmt.Errorf("redis: unexpected message type %v", msgType)
+	}
+	size, err := readInteger(reader)
+	if err != nil {
+		return "", nil, err
+	}
+	if size != 2 {
+		return "", nil, fmt.Errorf("redis: expected a cursor and results, got %v elements", size)
+	}
+	cursor, _, err := readBulkStringReply(reader)
+	if err != nil {
+		return "", nil, err
+	}
+	results, err := readStringsReply(reader)
+	if err != nil {
+		return "", nil, err
+	}
+	return cursor, results, nil
+}
@@ -0,0 +1,192 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestClient_ScanAll(t *testing.T) {
+	t.Parallel()
+	t.Run("Follows the cursor until it returns to 0", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, requests := recordingConn(t,
+			asArray(asBulkString("17"), asArray(asBulkString("a"), asBulkString("b"))),
+			asArray(asBulkString("9"), asArray()),
+			asArray(asBulkString("0"), asArray(asBulkString("c"))),
+		)
+		client.pool <- conn
+
+		got, err := client.ScanAll(context.Background(), ScanOptions{Match: "*", Count: 100, Type: "string"})
+
+		if err != nil {
+			t.Errorf("ScanAll() error = %v", err)
+		}
+		if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("ScanAll() got = %v, want %v", got, want)
+		}
+		for _, cursor := range []string{"0", "17", "9"} {
+			want := string(command("SCAN", cursor, "MATCH", "*", "COUNT", "100", "TYPE", "string"))
+			if got := <-requests; got != want {
+				t.Errorf("ScanAll() sent = %q, want %q", got, want)
+			}
+		}
+	})
+	t.Run("Errors stop the iteration", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t,
+			asArray(asBulkString("17"), asArray(asBulkString("a"))),
+			asSimpleErrorString("ERR invalid cursor"),
+		)
+
+		got, err := client.ScanAll(context.Background(), ScanOptions{})
+
+		if err == nil || err.Error() != "ERR invalid cursor" {
+			t.Errorf("ScanAll() error = %v, wantErr %v", err, "ERR invalid cursor")
+		}
+		if want := []string{"a"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("ScanAll() got = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestScanIterator_Cursor(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, requests := recordingConn(t,
+		asArray(asBulkString("17"), asArray(asBulkString("a"), asBulkString("b"))),
+		asArray(asBulkString("0"), asArray(asBulkString("c"))),
+	)
+	client.pool <- conn
+
+	it := client.Scan(context.Background(), ScanOptions{})
+	if it.Cursor() != "" {
+		t.Errorf("Cursor() before Next = %q, want empty", it.Cursor())
+	}
+	it.Next()
+	if got := it.Cursor(); got != "" {
+		t.Errorf("Cursor() part way through the first batch = %q, want empty to start over", got)
+	}
+	it.Next()
+	if got := it.Cursor(); got != "17" {
+		t.Errorf("Cursor() after the batch = %q, want %q", got, "17")
+	}
+
+	// resume in a new iterator, as after a restart
+	<-requests
+	resumed := client.ScanFrom(context.Background(), it.Cursor(), ScanOptions{})
+	var got []string
+	for resumed.Next() {
+		got = append(got, resumed.Key())
+	}
+
+	if resumed.Err() != nil || !reflect.DeepEqual(got, []string{"c"}) {
+		t.Errorf("ScanFrom() got = %v, error = %v", got, resumed.Err())
+	}
+	if want := string(command("SCAN", "17")); <-requests != want {
+		t.Errorf("ScanFrom() should have sent %q", want)
+	}
+	if resumed.Cursor() != "0" {
+		t.Errorf("Cursor() once complete = %q, want %q", resumed.Cursor(), "0")
+	}
+
+	// a cursor saved once complete resumes to nothing, rather than starting over, which would fail here with no replies left
+	done := client.ScanFrom(context.Background(), resumed.Cursor(), ScanOptions{})
+	if done.Next() || done.Err() != nil {
+		t.Errorf("ScanFrom() of a complete cursor got %q, error = %v, want nothing", done.Key(), done.Err())
+	}
+	if done.Cursor() != "0" {
+		t.Errorf("Cursor() of a complete cursor = %q, want %q", done.Cursor(), "0")
+	}
+}
+
+func TestClient_DeleteMatching(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, requests := recordingConn(t,
+		asArray(asBulkString("17"), asArray(asBulkString("a"), asBulkString("b"), asBulkString("c"))),
+		asInteger(2),
+		asArray(asBulkString("0"), asArray()),
+		asInteger(1),
+	)
+	client.pool <- conn
+
+	got, err := client.DeleteMatching(context.Background(), "session:*", 2)
+
+	if err != nil || got != 3 {
+		t.Errorf("DeleteMatching() got = %v, error = %v", got, err)
+	}
+	want := []string{
+		string(command("SCAN", "0", "MATCH", "session:*", "COUNT", "2")),
+		string(command("UNLINK", "a", "b")),
+		string(command("SCAN", "17", "MATCH", "session:*", "COUNT", "2")),
+		string(command("UNLINK", "c")),
+	}
+	for _, want := range want {
+		if got := <-requests; got != want {
+			t.Errorf("DeleteMatching() sent = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestClient_ClusterScan(t *testing.T) {
+	t.Parallel()
+	node := func(address string) []byte {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, _ := strconv.Atoi(port)
+		return asArray(asBulkString(host), asInteger(int64(n)))
+	}
+	first, firstRequests := listen(t, asArray(asBulkString("0"), asArray(asBulkString("a"), asBulkString("b"))))
+	second, _ := listen(t, asArray(asBulkString("0"), asArray(asBulkString("c"))))
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pool <- fakeConn(t, asArray(
+		asArray(asInteger(0), asInteger(5000), node(first)),
+		asArray(asInteger(5001), asInteger(10000), node(second)),
+		// a master serving several ranges is only scanned once
+		asArray(asInteger(10001), asInteger(16383), node(first)),
+	))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	it := client.ClusterScan(ctx, ScanOptions{Match: "*"})
+	var got []string
+	for it.Next() {
+		got = append(got, it.Key())
+	}
+
+	if it.Err() != nil {
+		t.Errorf("ClusterScan() error = %v", it.Err())
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ClusterScan() got = %v, want %v", got, want)
+	}
+	if want := string(command("SCAN", "0", "MATCH", "*")); <-firstRequests != want {
+		t.Errorf("ClusterScan() should have sent %q to each master", want)
+	}
+	if len(firstRequests) != 0 {
+		t.Errorf("ClusterScan() should only scan each master once")
+	}
+}
@@ -0,0 +1,356 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandCount returns the number of commands the server supports, including those added by modules.
+func (c *Client) CommandCount(ctx context.Context) (int64, error) {
+	return c.execInteger(ctx, "COMMAND", "COUNT")
+}
+
+// CommandList returns the names of every command the server supports, including those added by modules.
+// It is built from the full COMMAND reply, so it works against servers older than COMMAND LIST.
+func (c *Client) CommandList(ctx context.Context) ([]string, error) {
+	var names []string
+	err := c.exec(ctx, []string{"COMMAND"}, func(reader *bufio.Reader) error {
+		reply, err := readReply(reader)
+		if err != nil {
+			return err
+		}
+		commands, ok := reply.([]interface{})
+		if !ok {
+			return fmt.Errorf("redis: expected an array of commands but got: %v", reply)
+		}
+		names = make([]string, 0, len(commands))
+		for _, cmd := range commands {
+			info, ok := cmd.([]interface{})
+			if !ok || len(info) == 0 {
+				return fmt.Errorf("redis: expected command info but got: %v", cmd)
+			}
+			name, ok := info[0].(string)
+			if !ok {
+				return fmt.Errorf("redis: expected a command name but got: %v", info[0])
+			}
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
+
+// WithNoTouch runs CLIENT NO-TOUCH on every connection, so commands from this Client don't update the last access
+// time of the keys they read, and don't skew LRU/LFU eviction. It suits admin tools scanning the keyspace.
+// Requires Redis 7.2.
+func WithNoTouch() Option {
+	return func(c *Client) {
+		c.onConnect = append(c.onConnect, []string{"CLIENT", "NO-TOUCH", "ON"})
+	}
+}
+
+// WithClientName names every connection with CLIENT SETNAME, so they can be told apart in CLIENT LIST.
+// Redis rejects names containing spaces.
+func WithClientName(name string) Option {
+	return func(c *Client) {
+		c.onConnect = append(c.onConnect, []string{"CLIENT", "SETNAME", name})
+	}
+}
+
+// WithClientNameTemplate is like WithClientName, but replaces {host} in template with the hostname and {pid} with
+// the process ID, so each process gets a name of its own, such as "myapp-{host}-{pid}".
+func WithClientNameTemplate(template string) Option {
+	host, _ := os.Hostname()
+	name := strings.NewReplacer("{host}", host, "{pid}", strconv.Itoa(os.Getpid())).Replace(template)
+	return WithClientName(name)
+}
+
+// ClientNoEvict turns CLIENT NO-EVICT on or off, which protects a connection from being evicted under memory pressure.
+// Note it applies to the one connection the command happens to run on, so it is only meaningful on a Client
+// limited to a single connection. Requires Redis 7.0.
+func (c *Client) ClientNoEvict(ctx context.Context, on bool) error {
+	mode := "OFF"
+	if on {
+		mode = "ON"
+	}
+	return c.execOK(ctx, "CLIENT", "NO-EVICT", mode)
+}
+
+// ServerInfo describes the Redis server a Client is connected to.
+type ServerInfo struct {
+	// Version is the Redis version, e.g. "7.2.4"
+	Version string
+	// Mode is standalone, cluster or sentinel
+	Mode string
+	// Role is master or replica
+	Role string
+}
+
+type serverInfoCache struct {
+	mu   sync.Mutex
+	info *ServerInfo
+}
+
+// ServerInfo returns the version, mode and role of the server, from HELLO, or INFO on servers older than Redis 6.0.
+// The first successful result is cached for the life of the Client, so it is cheap to call before every optional
+// command. Note the role can change on failover, which the cached result won't reflect.
+//
+// Once the version is known, commands the server is too old for fail with ErrUnsupportedCommand without being sent,
+// so calling ServerInfo at startup turns confusing replies from old servers into a clear error.
+func (c *Client) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	if cached := c.serverInfo.get(); cached != nil {
+		return *cached, nil
+	}
+	info, err := c.hello(ctx)
+	if isRedisError(err) {
+		info, err = c.info(ctx)
+	}
+	if err != nil {
+		return ServerInfo{}, err
+	}
+	c.serverInfo.set(info)
+	return info, nil
+}
+
+func (s *serverInfoCache) get() *ServerInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info
+}
+
+func (s *serverInfoCache) set(info ServerInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info = &info
+}
+
+func (c *Client) hello(ctx context.Context) (ServerInfo, error) {
+	var info ServerInfo
+	err := c.exec(ctx, []string{"HELLO"}, func(reader *bufio.Reader) error {
+		reply, err := readReply(reader)
+		if err != nil {
+			return err
+		}
+		fields, ok := reply.([]interface{})
+		if !ok || len(fields)%2 != 0 {
+			return fmt.Errorf("redis: expected HELLO field value pairs but got: %v", reply)
+		}
+		for i := 0; i < len(fields); i += 2 {
+			name, _ := fields[i].(string)
+			value, _ := fields[i+1].(string)
+			switch name {
+			case "version":
+				info.Version = value
+			case "mode":
+				info.Mode = value
+			case "role":
+				info.Role = value
+			}
+		}
+		return nil
+	})
+	return info, err
+}
+
+func (c *Client) info(ctx context.Context) (ServerInfo, error) {
+	var info ServerInfo
+	cmds := [][]string{{"INFO", "server"}, {"INFO", "replication"}}
+	err := c.execPipeline(ctx, cmds, func(reader *bufio.Reader) error {
+		var sections []string
+		var firstErr error
+		for range cmds {
+			section, _, err := readBulkStringReply(reader)
+			if err != nil && !isRedisError(err) {
+				return err
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			sections = append(sections, section)
+		}
+		if firstErr != nil {
+			return firstErr
+		}
+		fields := parseInfo(strings.Join(sections, "\r\n"))
+		info = ServerInfo{Version: fields["redis_version"], Mode: fields["redis_mode"], Role: fields["role"]}
+		if info.Role == "slave" {
+			info.Role = "replica"
+		}
+		return nil
+	})
+	return info, err
+}
+
+// parseInfo parses the field:value lines of an INFO reply, skipping # section headers.
+func parseInfo(s string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(s, "\r\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, value, ok := cut(line, ":"); ok {
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+// ReplicationOffset returns master_repl_offset from INFO replication: on a primary, how far its replication stream
+// has got, and on a replica, how much of it has been received. Polling replicas until their offset passes the
+// primary's offset after a write confirms it propagated, without the blocking of WAIT.
+func (c *Client) ReplicationOffset(ctx context.Context) (int64, error) {
+	var offset int64
+	err := c.exec(ctx, []string{"INFO", "replication"}, func(reader *bufio.Reader) error {
+		section, _, err := readBulkStringReply(reader)
+		if err != nil {
+			return err
+		}
+		value, ok := parseInfo(section)["master_repl_offset"]
+		if !ok {
+			return fmt.Errorf("redis: INFO replication has no master_repl_offset")
+		}
+		offset, err = strconv.ParseInt(value, 10, 64)
+		return err
+	})
+	return offset, err
+}
+
+// LatencyEvent is the latest spike the latency monitor recorded for one event, such as "command" or "fork".
+type LatencyEvent struct {
+	Event string
+	// Timestamp is when the latest spike happened
+	Timestamp time.Time
+	// LatestMs is the latency of the latest spike, in milliseconds
+	LatestMs int64
+	// MaxMs is the highest latency recorded for the event, in milliseconds
+	MaxMs int64
+}
+
+// LatencyLatest returns the latest spike of every event the latency monitor has recorded, from LATENCY LATEST.
+// Nothing is recorded unless latency-monitor-threshold is set on the server.
+func (c *Client) LatencyLatest(ctx context.Context) ([]LatencyEvent, error) {
+	var events []LatencyEvent
+	err := c.exec(ctx, []string{"LATENCY", "LATEST"}, func(reader *bufio.Reader) error {
+		reply, err := readReply(reader)
+		if err != nil {
+			return err
+		}
+		entries, ok := reply.([]interface{})
+		if !ok && reply != nil {
+			return fmt.Errorf("redis: expected an array of latency events but got: %v", reply)
+		}
+		events = make([]LatencyEvent, 0, len(entries))
+		for _, entry := range entries {
+			fields, ok := entry.([]interface{})
+			if !ok || len(fields) < 4 {
+				return fmt.Errorf("redis: expected a latency event but got: %v", entry)
+			}
+			event, ok1 := fields[0].(string)
+			timestamp, ok2 := fields[1].(int64)
+			latest, ok3 := fields[2].(int64)
+			max, ok4 := fields[3].(int64)
+			if !ok1 || !ok2 || !ok3 || !ok4 {
+				return fmt.Errorf("redis: expected a latency event but got: %v", entry)
+			}
+			events = append(events, LatencyEvent{Event: event, Timestamp: time.Unix(timestamp, 0), LatestMs: latest, MaxMs: max})
+		}
+		return nil
+	})
+	return events, err
+}
+
+// LatencyReset clears every event the latency monitor has recorded, returning how many were cleared.
+func (c *Client) LatencyReset(ctx context.Context) (int64, error) {
+	return c.execInteger(ctx, "LATENCY", "RESET")
+}
+
+// MemoryDoctor returns the report of MEMORY DOCTOR, a human readable diagnosis of the server's memory use.
+func (c *Client) MemoryDoctor(ctx context.Context) (string, error) {
+	report, _, err := c.execBulkString(ctx, "MEMORY", "DOCTOR")
+	return report, err
+}
+
+// MemoryStats returns the memory usage metrics of MEMORY STATS, keyed by name, such as "peak.allocated".
+// Values are decoded as by Do, so counts are int64 and ratios are strings, except that the nested metrics of each
+// database, under "db.0" and so on, are a map[string]interface{} of their own.
+func (c *Client) MemoryStats(ctx context.Context) (map[string]interface{}, error) {
+	var stats map[string]interface{}
+	err := c.exec(ctx, []string{"MEMORY", "STATS"}, func(reader *bufio.Reader) error {
+		reply, err := readReply(reader)
+		if err != nil {
+			return err
+		}
+		stats, err = memoryStatsMap(reply)
+		return err
+	})
+	return stats, err
+}
+
+// memoryStatsMap turns a flat array of alternating names and values into a map, recursing into nested arrays.
+func memoryStatsMap(reply interface{}) (map[string]interface{}, error) {
+	fields, ok := reply.([]interface{})
+	if !ok || len(fields)%2 != 0 {
+		return nil, fmt.Errorf("redis: expected MEMORY STATS name value pairs but got: %v", reply)
+	}
+	stats := make(map[string]interface{}, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		name, ok := fields[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("redis: expected a MEMORY STATS name but got: %v", fields[i])
+		}
+		value := fields[i+1]
+		if _, nested := value.([]interface{}); nested {
+			var err error
+			if value, err = memoryStatsMap(value); err != nil {
+				return nil, err
+			}
+		}
+		stats[name] = value
+	}
+	return stats, nil
+}
+
+// BgSave starts writing an RDB snapshot in the background with BGSAVE, and returns the status Redis replies with,
+// such as "Background saving started". Poll LastSave to find out when the snapshot is done.
+func (c *Client) BgSave(ctx context.Context) (string, error) {
+	return c.execSimpleString(ctx, "BGSAVE")
+}
+
+// BgRewriteAOF starts rewriting the append only file in the background with BGREWRITEAOF, and returns the status
+// Redis replies with, such as "Background append only file rewriting started".
+func (c *Client) BgRewriteAOF(ctx context.Context) (string, error) {
+	return c.execSimpleString(ctx, "BGREWRITEAOF")
+}
+
+// LastSave returns when the last RDB snapshot was successfully written, at second precision. It moves forward once a
+// snapshot started by BgSave is done.
+func (c *Client) LastSave(ctx context.Context) (time.Time, error) {
+	unix, err := c.execInteger(ctx, "LASTSAVE")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// Shutdown stops the server with SHUTDOWN SAVE, or SHUTDOWN NOSAVE if save is false, which skips writing an RDB
+// snapshot, as suits a throwaway server in tests. The server closes the connection instead of replying, so that is
+// taken as success. An error reply, such as when the snapshot can't be written, is returned as usual.
+func (c *Client) Shutdown(ctx context.Context, save bool) error {
+	mode := "NOSAVE"
+	if save {
+		mode = "SAVE"
+	}
+	err := c.execOK(ctx, "SHUTDOWN", mode)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,306 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestServerCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "CommandCount",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CommandCount(ctx)
+			},
+			response:    asInteger(240),
+			wantCommand: []string{"COMMAND", "COUNT"},
+			want:        int64(240),
+		},
+		{
+			name: "CommandList",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CommandList(ctx)
+			},
+			response: asArray(
+				asArray(asBulkString("get"), asInteger(2), asArray(asSimpleString("readonly")), asInteger(1), asInteger(1), asInteger(1)),
+				asArray(asBulkString("set"), asInteger(-3), asArray(asSimpleString("write")), asInteger(1), asInteger(1), asInteger(1)),
+			),
+			wantCommand: []string{"COMMAND"},
+			want:        []string{"get", "set"},
+		},
+		{
+			name: "ClientNoEvict",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.ClientNoEvict(ctx, true)
+			},
+			response:    okString,
+			wantCommand: []string{"CLIENT", "NO-EVICT", "ON"},
+		},
+		{
+			name: "ServerInfo",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ServerInfo(ctx)
+			},
+			response: asArray(
+				asBulkString("server"), asBulkString("redis"),
+				asBulkString("version"), asBulkString("7.2.4"),
+				asBulkString("proto"), asInteger(2),
+				asBulkString("id"), asInteger(5),
+				asBulkString("mode"), asBulkString("standalone"),
+				asBulkString("role"), asBulkString("master"),
+				asBulkString("modules"), asArray(),
+			),
+			wantCommand: []string{"HELLO"},
+			want:        ServerInfo{Version: "7.2.4", Mode: "standalone", Role: "master"},
+		},
+		{
+			name: "ReplicationOffset",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ReplicationOffset(ctx)
+			},
+			response:    asBulkString("# Replication\r\nrole:master\r\nconnected_slaves:1\r\nmaster_repl_offset:3221225472\r\n"),
+			wantCommand: []string{"INFO", "replication"},
+			want:        int64(3221225472),
+		},
+		{
+			name: "ReplicationOffset missing",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ReplicationOffset(ctx)
+			},
+			response:    asBulkString("# Replication\r\nrole:master\r\n"),
+			wantCommand: []string{"INFO", "replication"},
+			want:        int64(0),
+			wantErr:     true,
+		},
+		{
+			name: "LatencyLatest",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.LatencyLatest(ctx)
+			},
+			response: asArray(
+				asArray(asBulkString("command"), asInteger(1700000000), asInteger(120), asInteger(350)),
+				asArray(asBulkString("fork"), asInteger(1700000100), asInteger(15), asInteger(15)),
+			),
+			wantCommand: []string{"LATENCY", "LATEST"},
+			want: []LatencyEvent{
+				{Event: "command", Timestamp: time.Unix(1700000000, 0), LatestMs: 120, MaxMs: 350},
+				{Event: "fork", Timestamp: time.Unix(1700000100, 0), LatestMs: 15, MaxMs: 15},
+			},
+		},
+		{
+			name: "LatencyLatest malformed",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.LatencyLatest(ctx)
+			},
+			response:    asArray(asArray(asBulkString("command"), asInteger(1700000000))),
+			wantCommand: []string{"LATENCY", "LATEST"},
+			want:        []LatencyEvent{},
+			wantErr:     true,
+		},
+		{
+			name: "MemoryDoctor",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.MemoryDoctor(ctx)
+			},
+			response:    asBulkString("Hi Sam, I can't find any memory issue in your instance."),
+			wantCommand: []string{"MEMORY", "DOCTOR"},
+			want:        "Hi Sam, I can't find any memory issue in your instance.",
+		},
+		{
+			name: "MemoryStats",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.MemoryStats(ctx)
+			},
+			response: asArray(
+				asBulkString("peak.allocated"), asInteger(1048576),
+				asBulkString("db.0"), asArray(
+					asBulkString("overhead.hashtable.main"), asInteger(72),
+					asBulkString("overhead.hashtable.expires"), asInteger(0),
+				),
+				asBulkString("fragmentation"), asBulkString("1.25"),
+			),
+			wantCommand: []string{"MEMORY", "STATS"},
+			want: map[string]interface{}{
+				"peak.allocated": int64(1048576),
+				"db.0":           map[string]interface{}{"overhead.hashtable.main": int64(72), "overhead.hashtable.expires": int64(0)},
+				"fragmentation":  "1.25",
+			},
+		},
+		{
+			name: "MemoryStats with an odd number of elements",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.MemoryStats(ctx)
+			},
+			response:    asArray(asBulkString("peak.allocated")),
+			wantCommand: []string{"MEMORY", "STATS"},
+			want:        map[string]interface{}(nil),
+			wantErr:     true,
+		},
+		{
+			name: "LatencyReset",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.LatencyReset(ctx)
+			},
+			response:    asInteger(2),
+			wantCommand: []string{"LATENCY", "RESET"},
+			want:        int64(2),
+		},
+		{
+			name: "BgSave",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.BgSave(ctx)
+			},
+			response:    asSimpleString("Background saving started"),
+			wantCommand: []string{"BGSAVE"},
+			want:        "Background saving started",
+		},
+		{
+			name: "BgRewriteAOF",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.BgRewriteAOF(ctx)
+			},
+			response:    asSimpleString("Background append only file rewriting started"),
+			wantCommand: []string{"BGREWRITEAOF"},
+			want:        "Background append only file rewriting started",
+		},
+		{
+			name: "LastSave",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.LastSave(ctx)
+			},
+			response:    asInteger(1700000000),
+			wantCommand: []string{"LASTSAVE"},
+			want:        time.Unix(1700000000, 0),
+		},
+		{
+			name: "BgSave already in progress",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.BgSave(ctx)
+			},
+			response:    asSimpleErrorString("ERR Background save already in progress"),
+			wantCommand: []string{"BGSAVE"},
+			want:        "",
+			wantErr:     true,
+		},
+	})
+}
+
+func TestClient_Shutdown(t *testing.T) {
+	t.Parallel()
+	t.Run("The server hanging up is success", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, serv := net.Pipe()
+		requests := make(chan string, 1)
+		go func() {
+			buf := make([]byte, 64)
+			n, _ := serv.Read(buf)
+			requests <- string(buf[:n])
+			_ = serv.Close()
+		}()
+		client.pool <- conn
+
+		err = client.Shutdown(context.Background(), false)
+
+		if err != nil {
+			t.Errorf("Shutdown() error = %v", err)
+		}
+		if want := string(command("SHUTDOWN", "NOSAVE")); <-requests != want {
+			t.Errorf("Shutdown() should have sent %q", want)
+		}
+	})
+	t.Run("Error replies are returned", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, requests := recordingConn(t, asSimpleErrorString("ERR Errors trying to SHUTDOWN. Check logs."))
+		client.pool <- conn
+
+		err = client.Shutdown(context.Background(), true)
+
+		if !isRedisError(err) {
+			t.Errorf("Shutdown() error = %v, want the error reply", err)
+		}
+		if want := string(command("SHUTDOWN", "SAVE")); <-requests != want {
+			t.Errorf("Shutdown() should have sent %q", want)
+		}
+	})
+}
+
+func TestClient_ServerInfo(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an old server without HELLO
+	client.pool <- fakeConn(t,
+		asSimpleErrorString("ERR unknown command 'HELLO'"),
+		append(
+			asBulkString("# Server\r\nredis_version:5.0.14\r\nredis_mode:standalone\r\n"),
+			asBulkString("# Replication\r\nrole:slave\r\nmaster_host:10.0.0.1\r\n")...,
+		),
+	)
+	want := ServerInfo{Version: "5.0.14", Mode: "standalone", Role: "replica"}
+
+	got, err := client.ServerInfo(context.Background())
+	if err != nil || got != want {
+		t.Errorf("ServerInfo() got = %v, error = %v, want %v", got, err, want)
+	}
+	// the fake conn has no responses left, so this must come from the cache
+	got, err = client.ServerInfo(context.Background())
+	if err != nil || got != want {
+		t.Errorf("ServerInfo() second call got = %v, error = %v, want %v", got, err, want)
+	}
+}
+
+func TestWithNoTouch(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1", WithNoTouch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, requests := recordingConn(t, okString)
+
+	err = client.setup(conn)
+
+	if err != nil {
+		t.Errorf("setup() error = %v", err)
+	}
+	if want := string(command("CLIENT", "NO-TOUCH", "ON")); <-requests != want {
+		t.Errorf("setup() should have sent %q", want)
+	}
+}
+
+func TestWithClientNameTemplate(t *testing.T) {
+	t.Parallel()
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skip("no hostname:", err)
+	}
+	client, err := New(context.Background(), "-1", WithClientNameTemplate("myapp-{host}-{pid}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, requests := recordingConn(t, okString)
+
+	err = client.setup(conn)
+
+	if err != nil {
+		t.Errorf("setup() error = %v", err)
+	}
+	name := "myapp-" + host + "-" + strconv.Itoa(os.Getpid())
+	if want := string(command("CLIENT", "SETNAME", name)); <-requests != want {
+		t.Errorf("setup() should have sent %q", want)
+	}
+}
@@ -0,0 +1,139 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+)
+
+// SInterStore stores the intersection of the sets at keys in dest, overwriting dest if it already exists.
+// It returns the number of members in the resulting set. Missing keys are treated as empty sets.
+func (c *Client) SInterStore(ctx context.Context, dest string, keys ...string) (int64, error) {
+	result, cmd := sInterStoreCmd(dest, keys)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sInterStoreCmd(dest string, keys []string) (*IntResult, pendingCmd) {
+	return newIntCmd(append([]string{"SINTERSTORE", dest}, keys...)...)
+}
+
+// SUnionStore stores the union of the sets at keys in dest, overwriting dest if it already exists.
+// It returns the number of members in the resulting set.
+func (c *Client) SUnionStore(ctx context.Context, dest string, keys ...string) (int64, error) {
+	result, cmd := sUnionStoreCmd(dest, keys)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sUnionStoreCmd(dest string, keys []string) (*IntResult, pendingCmd) {
+	return newIntCmd(append([]string{"SUNIONSTORE", dest}, keys...)...)
+}
+
+// SDiffStore stores the members of the first set at keys that are in none of the others in dest,
+// overwriting dest if it already exists. It returns the number of members in the resulting set.
+func (c *Client) SDiffStore(ctx context.Context, dest string, keys ...string) (int64, error) {
+	result, cmd := sDiffStoreCmd(dest, keys)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sDiffStoreCmd(dest string, keys []string) (*IntResult, pendingCmd) {
+	return newIntCmd(append([]string{"SDIFFSTORE", dest}, keys...)...)
+}
+
+// SUnion returns the members of the union of the sets at keys.
+func (c *Client) SUnion(ctx context.Context, keys ...string) ([]string, error) {
+	result, cmd := sUnionCmd(keys)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sUnionCmd(keys []string) (*StringsResult, pendingCmd) {
+	return newStringsCmd(append([]string{"SUNION"}, keys...)...)
+}
+
+// SInter returns the members of the intersection of the sets at keys. Missing keys are treated as empty sets.
+func (c *Client) SInter(ctx context.Context, keys ...string) ([]string, error) {
+	result, cmd := sInterCmd(keys)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sInterCmd(keys []string) (*StringsResult, pendingCmd) {
+	return newStringsCmd(append([]string{"SINTER"}, keys...)...)
+}
+
+// SDiff returns the members of the first set at keys that are in none of the others.
+func (c *Client) SDiff(ctx context.Context, keys ...string) ([]string, error) {
+	result, cmd := sDiffCmd(keys)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sDiffCmd(keys []string) (*StringsResult, pendingCmd) {
+	return newStringsCmd(append([]string{"SDIFF"}, keys...)...)
+}
+
+// SPop removes and returns up to count random members of the set at key. It returns an empty slice if key doesn't exist.
+func (c *Client) SPop(ctx context.Context, key string, count int64) ([]string, error) {
+	result, cmd := sPopCmd(key, count)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sPopCmd(key string, count int64) (*StringsResult, pendingCmd) {
+	return newStringsCmd("SPOP", key, strconv.FormatInt(count, 10))
+}
+
+// SRandMember returns up to count random members of the set at key without removing them.
+// A negative count returns exactly -count members, which may include the same member more than once.
+func (c *Client) SRandMember(ctx context.Context, key string, count int64) ([]string, error) {
+	result, cmd := sRandMemberCmd(key, count)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sRandMemberCmd(key string, count int64) (*StringsResult, pendingCmd) {
+	return newStringsCmd("SRANDMEMBER", key, strconv.FormatInt(count, 10))
+}
+
+// SMembers returns every member of the set at key.
+func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	result, cmd := sMembersCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sMembersCmd(key string) (*StringsResult, pendingCmd) {
+	return newStringsCmd("SMEMBERS", key)
+}
+
+// SMove atomically moves member from the set at src to the set at dst. It reports whether the move happened, which
+// is false if member wasn't in src.
+func (c *Client) SMove(ctx context.Context, src, dst, member string) (bool, error) {
+	result, cmd := sMoveCmd(src, dst, member)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sMoveCmd(src, dst, member string) (*BoolResult, pendingCmd) {
+	return newBoolCmd("SMOVE", src, dst, member)
+}
+
+// SInterCard returns the number of members in the intersection of the sets at keys, without building it. Counting
+// stops once it reaches limit, which saves work when only a threshold matters, and a limit of 0 counts every member.
+// Requires Redis 7.0.
+func (c *Client) SInterCard(ctx context.Context, limit int64, keys ...string) (int64, error) {
+	result, cmd := sInterCardCmd(limit, keys)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func sInterCardCmd(limit int64, keys []string) (*IntResult, pendingCmd) {
+	return newIntCmd(interCardArgs("SINTERCARD", limit, keys)...)
+}
+
+func interCardArgs(name string, limit int64, keys []string) []string {
+	args := append([]string{name, strconv.Itoa(len(keys))}, keys...)
+	return append(args, "LIMIT", strconv.FormatInt(limit, 10))
+}
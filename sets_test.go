@@ -0,0 +1,130 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "SInterCard",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SInterCard(ctx, 100, "a", "b")
+			},
+			response:    asInteger(42),
+			wantCommand: []string{"SINTERCARD", "2", "a", "b", "LIMIT", "100"},
+			want:        int64(42),
+		},
+		{
+			name: "SMove",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SMove(ctx, "active", "inactive", "m")
+			},
+			response:    asInteger(1),
+			wantCommand: []string{"SMOVE", "active", "inactive", "m"},
+			want:        true,
+		},
+		{
+			name: "SMove of a missing member",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SMove(ctx, "active", "inactive", "m")
+			},
+			response:    asInteger(0),
+			wantCommand: []string{"SMOVE", "active", "inactive", "m"},
+			want:        false,
+		},
+		{
+			name: "SInterStore",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SInterStore(ctx, "dest", "a", "b")
+			},
+			response:    asInteger(2),
+			wantCommand: []string{"SINTERSTORE", "dest", "a", "b"},
+			want:        int64(2),
+		},
+		{
+			name: "SUnionStore",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SUnionStore(ctx, "dest", "a", "b")
+			},
+			response:    asInteger(5),
+			wantCommand: []string{"SUNIONSTORE", "dest", "a", "b"},
+			want:        int64(5),
+		},
+		{
+			name: "SDiffStore",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SDiffStore(ctx, "dest", "a", "b")
+			},
+			response:    asInteger(0),
+			wantCommand: []string{"SDIFFSTORE", "dest", "a", "b"},
+			want:        int64(0),
+		},
+		{
+			name: "SInterStore errors",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SInterStore(ctx, "dest", "a")
+			},
+			response:    asSimpleErrorString("WRONGTYPE Operation against a key holding the wrong kind of value"),
+			wantCommand: []string{"SINTERSTORE", "dest", "a"},
+			want:        int64(0),
+			wantErr:     true,
+		},
+		{
+			name: "SUnion",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SUnion(ctx, "a", "b")
+			},
+			response:    asArray(asBulkString("x"), asBulkString("y")),
+			wantCommand: []string{"SUNION", "a", "b"},
+			want:        []string{"x", "y"},
+		},
+		{
+			name: "SInter",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SInter(ctx, "a", "b")
+			},
+			response:    asArray(asBulkString("x")),
+			wantCommand: []string{"SINTER", "a", "b"},
+			want:        []string{"x"},
+		},
+		{
+			name: "SDiff returns an empty slice for an empty result",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SDiff(ctx, "a", "b")
+			},
+			response:    asArray(),
+			wantCommand: []string{"SDIFF", "a", "b"},
+			want:        []string{},
+		},
+		{
+			name: "SPop",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SPop(ctx, "a", 2)
+			},
+			response:    asArray(asBulkString("x"), asBulkString("y")),
+			wantCommand: []string{"SPOP", "a", "2"},
+			want:        []string{"x", "y"},
+		},
+		{
+			name: "SRandMember with a negative count",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SRandMember(ctx, "a", -3)
+			},
+			response:    asArray(asBulkString("x"), asBulkString("x"), asBulkString("y")),
+			wantCommand: []string{"SRANDMEMBER", "a", "-3"},
+			want:        []string{"x", "x", "y"},
+		},
+		{
+			name: "SMembers",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.SMembers(ctx, "s")
+			},
+			response:    asArray(asBulkString("a")),
+			wantCommand: []string{"SMEMBERS", "s"},
+			want:        []string{"a"},
+		},
+	})
+}
@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"net"
+)
+
+// WithoutPool makes the Client keep a single connection instead of a pool, redialing it whenever an error leaves it
+// unusable. Commands take turns on it, each waiting for the one before to finish or for its own context to be done.
+// Connection state, such as a database switched to with SELECT or a MULTI sent through Do, carries over from one
+// command to the next until the connection is redialed, which suits command line tools that think in terms of one
+// connection. Subscribe isn't available, as it would take the connection for itself.
+func WithoutPool() Option {
+	return func(c *Client) {
+		c.maxConns = 1
+		c.single = &singleConn{turn: make(chan struct{}, 1)}
+	}
+}
+
+// singleConn is the connection of a Client made WithoutPool. Whoever holds a slot in turn may use conn.
+type singleConn struct {
+	turn chan struct{}
+	// conn is nil until the first command, and after an error closed it
+	conn net.Conn
+}
+
+// getSingle is getConn for a Client made WithoutPool.
+func (c *Client) getSingle(ctx context.Context) (net.Conn, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case c.single.turn <- struct{}{}:
+	}
+	if conn := c.single.conn; conn != nil {
+		if c.prepare(ctx, conn) {
+			return conn, nil
+		}
+		c.single.conn = nil
+	}
+	select {
+	case <-ctx.Done():
+		<-c.single.turn
+		return nil, ctx.Err()
+	case c.sem <- struct{}{}:
+	}
+	conn, err := c.dial(ctx)
+	if err != nil {
+		<-c.single.turn
+		return nil, err
+	}
+	c.single.conn = conn
+	return conn, nil
+}
+
+// putSingle is putConn for a Client made WithoutPool, closing the connection under the same conditions.
+// conn is nil when a Conn gives back its turn after closing the connection itself.
+func (c *Client) putSingle(conn net.Conn, err error) {
+	c.lifecycle.mu.Lock()
+	closed := c.lifecycle.closed
+	c.lifecycle.mu.Unlock()
+	if conn == nil || (err != nil && !isRedisError(err)) || closed {
+		if conn != nil {
+			_ = conn.Close()
+		}
+		c.single.conn = nil
+	}
+	<-c.single.turn
+}
+
+// closeIdle closes the connection, unless a command is using it, in which case putSingle closes it once the Client
+// is closed.
+func (s *singleConn) closeIdle() {
+	select {
+	case s.turn <- struct{}{}:
+	default:
+		return
+	}
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	<-s.turn
+}
@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWithoutPool(t *testing.T) {
+	t.Parallel()
+	t.Run("Commands share one connection and its state", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithoutPool())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, requests := recordingConn(t, okString, asSimpleString("QUEUED"), asArray(asInteger(1)))
+		client.single.conn = conn
+
+		for _, args := range [][]interface{}{{"MULTI"}, {"INCR", "a"}, {"EXEC"}} {
+			// a pooled connection would refuse QUEUED with ErrUnexpectedQueued
+			if _, err := client.DoArgs(context.Background(), args...); err != nil {
+				t.Fatalf("DoArgs(%v) error = %v", args, err)
+			}
+			<-requests
+		}
+		if client.single.conn != conn {
+			t.Errorf("the connection should have been kept")
+		}
+		if got := client.PoolSize(); got != 1 {
+			t.Errorf("PoolSize() = %v, want %v", got, 1)
+		}
+	})
+	t.Run("Redials after an error", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), echoServer(t), WithoutPool())
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.single.conn = brokenConn(t)
+
+		if _, _, err := client.Get(context.Background(), "k"); err == nil {
+			t.Fatalf("Get() on a broken connection should fail")
+		}
+		got, _, err := client.Get(context.Background(), "k")
+
+		if err != nil || got != "k" {
+			t.Errorf("Get() got = %v, %v, want %v", got, err, "k")
+		}
+	})
+	t.Run("Concurrent commands take turns", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), echoServer(t), WithoutPool())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 20; i++ {
+					key := strconv.Itoa(g) + ":" + strconv.Itoa(i)
+					if got, _, err := client.Get(context.Background(), key); err != nil || got != key {
+						t.Errorf("Get(%v) = %v, %v", key, got, err)
+						return
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		if err := client.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if client.single.conn != nil {
+			t.Errorf("Close() should have closed the connection")
+		}
+	})
+	t.Run("A Conn gives back the turn after Select", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), echoServer(t), WithoutPool())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := client.Conn(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the echo server replies with a bulk string, which SELECT turns into an error, but the turn is what matters
+		_ = conn.Select(context.Background(), 1)
+		if err := conn.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, _, err := client.Get(context.Background(), "k"); err != nil || got != "k" {
+			t.Errorf("Get() got = %v, %v, want %v", got, err, "k")
+		}
+	})
+	t.Run("Subscribe is refused", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1", WithoutPool())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Subscribe(context.Background(), []string{"ch"}); err == nil {
+			t.Errorf("Subscribe() should have failed")
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidLexBound is returned for a lexical range boundary that doesn't start with [ or (, and isn't - or +.
+var ErrInvalidLexBound = errors.New("redis: invalid lexical range boundary")
+
+// ZRange returns the members of the sorted set at key ranked between start and stop, inclusive, from the lowest score.
+// Negative indices count from the highest score, so ZRange(ctx, key, 0, -1) returns every member.
+func (c *Client) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	result, cmd := zRangeCmd(key, start, stop)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func zRangeCmd(key string, start, stop int64) (*StringsResult, pendingCmd) {
+	return newStringsCmd("ZRANGE", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+}
+
+// ZInterCard returns the number of members in the intersection of the sorted sets at keys, without building it.
+// Counting stops once it reaches limit, and a limit of 0 counts every member. Requires Redis 7.0.
+func (c *Client) ZInterCard(ctx context.Context, limit int64, keys ...string) (int64, error) {
+	result, cmd := zInterCardCmd(limit, keys)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func zInterCardCmd(limit int64, keys []string) (*IntResult, pendingCmd) {
+	return newIntCmd(interCardArgs("ZINTERCARD", limit, keys)...)
+}
+
+// LexRangeOptions narrows the members returned by ZRangeByLex.
+type LexRangeOptions struct {
+	// Limit skips the first Offset matching members and returns at most Count. A negative Count returns every
+	// member after Offset.
+	Limit         bool
+	Offset, Count int64
+}
+
+// ZRangeByLex returns the members of the sorted set at key between min and max, in lexical order. It is only
+// meaningful when every member has the same score. Each boundary is a member prefixed with [ to include it or ( to
+// exclude it, or - and + for the lowest and highest possible members, so members starting with "app" are
+//
+//	client.ZRangeByLex(ctx, key, "[app", "[app\xff", redis.LexRangeOptions{})
+//
+// Boundaries in any other form fail with ErrInvalidLexBound without being sent.
+func (c *Client) ZRangeByLex(ctx context.Context, key, min, max string, opts LexRangeOptions) ([]string, error) {
+	result, cmd := zRangeByLexCmd(key, min, max, opts)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func zRangeByLexCmd(key, min, max string, opts LexRangeOptions) (*StringsResult, pendingCmd) {
+	for _, bound := range []string{min, max} {
+		if err := checkLexBound(bound); err != nil {
+			result, cmd := newStringsCmd()
+			cmd.fail(err)
+			return result, cmd
+		}
+	}
+	args := []string{"ZRANGEBYLEX", key, min, max}
+	if opts.Limit {
+		args = append(args, "LIMIT", strconv.FormatInt(opts.Offset, 10), strconv.FormatInt(opts.Count, 10))
+	}
+	return newStringsCmd(args...)
+}
+
+func checkLexBound(bound string) error {
+	if bound == "-" || bound == "+" || bound != "" && (bound[0] == '[' || bound[0] == '(') {
+		return nil
+	}
+	return fmt.Errorf("%w %q: must be -, + or start with [ or (", ErrInvalidLexBound, bound)
+}
+
+// ZMember is a member of a sorted set and its score.
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// ZMPop pops up to count members from the first non-empty sorted set of keys, the lowest scoring if min is set and
+// the highest otherwise. It returns the key they were popped from, and ok is false if every sorted set was empty.
+// Requires Redis 7.0.
+func (c *Client) ZMPop(ctx context.Context, keys []string, min bool, count int64) (key string, members []ZMember, ok bool, err error) {
+	args := append([]string{"ZMPOP", strconv.Itoa(len(keys))}, keys...)
+	return c.zmpop(ctx, append(args, zmpopArgs(min, count)...))
+}
+
+// BZMPop is the blocking form of ZMPop: if every sorted set is empty it waits up to timeout, or forever if timeout
+// is zero, for a member to be added, and ok is false if none was. Requires Redis 7.0.
+//
+// The reply is waited for until timeout has passed, even beyond the deadline of ctx, so a member Redis pops at the
+// last moment isn't lost. Cancelling ctx still interrupts it, as does its deadline when timeout is zero.
+func (c *Client) BZMPop(ctx context.Context, timeout time.Duration, keys []string, min bool, count int64) (key string, members []ZMember, ok bool, err error) {
+	args := append([]string{"BZMPOP", formatFloat(timeout.Seconds()), strconv.Itoa(len(keys))}, keys...)
+	return c.zmpop(ctx, append(args, zmpopArgs(min, count)...))
+}
+
+func zmpopArgs(min bool, count int64) []string {
+	where := "MAX"
+	if min {
+		where = "MIN"
+	}
+	return []string{where, "COUNT", strconv.FormatInt(count, 10)}
+}
+
+// zmpop sends args and reads the reply of ZMPOP and BZMPOP: nil, or the key and an array of member and score pairs.
+func (c *Client) zmpop(ctx context.Context, args []string) (key string, members []ZMember, ok bool, err error) {
+	err = c.exec(ctx, args, func(reader *bufio.Reader) error {
+		reply, err := readReply(reader)
+		if err != nil || reply == nil {
+			return err
+		}
+		fields, isArray := reply.([]interface{})
+		if !isArray || len(fields) != 2 {
+			return fmt.Errorf("redis: expected a key and members but got: %v", reply)
+		}
+		name, isString := fields[0].(string)
+		pairs, isArray := fields[1].([]interface{})
+		if !isString || !isArray {
+			return fmt.Errorf("redis: expected a key and members but got: %v", reply)
+		}
+		members = make([]ZMember, 0, len(pairs))
+		for _, pair := range pairs {
+			member, err := parseZMember(pair)
+			if err != nil {
+				return err
+			}
+			members = append(members, member)
+		}
+		key, ok = c.stripKeyPrefix(name), true
+		return nil
+	})
+	return key, members, ok, err
+}
+
+func parseZMember(pair interface{}) (ZMember, error) {
+	fields, ok := pair.([]interface{})
+	if !ok || len(fields) != 2 {
+		return ZMember{}, fmt.Errorf("redis: expected a member and score but got: %v", pair)
+	}
+	member, ok1 := fields[0].(string)
+	score, ok2 := fields[1].(string)
+	if !ok1 || !ok2 {
+		return ZMember{}, fmt.Errorf("redis: expected a member and score but got: %v", pair)
+	}
+	f, err := strconv.ParseFloat(score, 64)
+	if err != nil {
+		return ZMember{}, err
+	}
+	return ZMember{Member: member, Score: f}, nil
+}
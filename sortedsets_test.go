@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSortedSetCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "ZInterCard",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ZInterCard(ctx, 0, "z1", "z2", "z3")
+			},
+			response:    asInteger(7),
+			wantCommand: []string{"ZINTERCARD", "3", "z1", "z2", "z3", "LIMIT", "0"},
+			want:        int64(7),
+		},
+		{
+			name: "ZRange",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ZRange(ctx, "z", 0, -1)
+			},
+			response:    asArray(asBulkString("low"), asBulkString("high")),
+			wantCommand: []string{"ZRANGE", "z", "0", "-1"},
+			want:        []string{"low", "high"},
+		},
+		{
+			name: "ZRangeByLex",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ZRangeByLex(ctx, "terms", "[app", "(apq", LexRangeOptions{})
+			},
+			response:    asArray(asBulkString("apple"), asBulkString("apply")),
+			wantCommand: []string{"ZRANGEBYLEX", "terms", "[app", "(apq"},
+			want:        []string{"apple", "apply"},
+		},
+		{
+			name: "ZRangeByLex with limit",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ZRangeByLex(ctx, "terms", "-", "+", LexRangeOptions{Limit: true, Offset: 10, Count: 5})
+			},
+			response:    asArray(),
+			wantCommand: []string{"ZRANGEBYLEX", "terms", "-", "+", "LIMIT", "10", "5"},
+			want:        []string{},
+		},
+		{
+			name: "ZMPop",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				key, members, ok, err := c.ZMPop(ctx, []string{"high", "low"}, true, 2)
+				return []interface{}{key, members, ok}, err
+			},
+			response: asArray(
+				asBulkString("low"),
+				asArray(
+					asArray(asBulkString("job1"), asBulkString("1")),
+					asArray(asBulkString("job2"), asBulkString("2.5")),
+				),
+			),
+			wantCommand: []string{"ZMPOP", "2", "high", "low", "MIN", "COUNT", "2"},
+			want:        []interface{}{"low", []ZMember{{"job1", 1}, {"job2", 2.5}}, true},
+		},
+		{
+			name: "ZMPop empty",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				key, members, ok, err := c.ZMPop(ctx, []string{"queue"}, false, 1)
+				return []interface{}{key, members, ok}, err
+			},
+			response:    []byte("*-1\r\n"),
+			wantCommand: []string{"ZMPOP", "1", "queue", "MAX", "COUNT", "1"},
+			want:        []interface{}{"", []ZMember(nil), false},
+		},
+		{
+			name: "BZMPop",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				key, members, ok, err := c.BZMPop(ctx, 1500*time.Millisecond, []string{"queue"}, false, 1)
+				return []interface{}{key, members, ok}, err
+			},
+			response:    asArray(asBulkString("queue"), asArray(asArray(asBulkString("job"), asBulkString("inf")))),
+			wantCommand: []string{"BZMPOP", "1.5", "1", "queue", "MAX", "COUNT", "1"},
+			want:        []interface{}{"queue", []ZMember{{"job", math.Inf(1)}}, true},
+		},
+		{
+			name: "BZMPop malformed score",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				_, _, _, err := c.BZMPop(ctx, 0, []string{"queue"}, true, 1)
+				return nil, err
+			},
+			response:    asArray(asBulkString("queue"), asArray(asArray(asBulkString("job"), asBulkString("high")))),
+			wantCommand: []string{"BZMPOP", "0", "1", "queue", "MIN", "COUNT", "1"},
+			wantErr:     true,
+		},
+	})
+}
+
+func TestZRangeByLex_InvalidBound(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bounds := range [][2]string{{"app", "+"}, {"-", ""}, {"[a", "z"}} {
+		_, err := client.ZRangeByLex(context.Background(), "terms", bounds[0], bounds[1], LexRangeOptions{})
+		if !errors.Is(err, ErrInvalidLexBound) {
+			t.Errorf("ZRangeByLex(%q, %q) error = %v, want ErrInvalidLexBound", bounds[0], bounds[1], err)
+		}
+	}
+}
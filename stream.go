@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync/atomic"
+)
+
+// SetWriter streams a value of exactly size bytes into key, without holding it in memory, as a SET.
+// Close must be called once size bytes have been written, and returns the result of the SET.
+// Closing early, or writing more than size bytes, fails and discards the connection.
+//
+// The connection is checked out until Close, with the deadline from ctx. The SET isn't retried, and isn't seen
+// by hooks or metrics.
+func (c *Client) SetWriter(ctx context.Context, key string, size int64) (io.WriteCloser, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("redis: SetWriter size must not be negative, got %v", size)
+	}
+	if err := c.checkAllowed([][]string{{"SET"}}); err != nil {
+		return nil, err
+	}
+	if err := c.lifecycle.begin(); err != nil {
+		return nil, err
+	}
+	conn, err := c.getConn(ctx)
+	if err != nil {
+		c.lifecycle.end()
+		return nil, err
+	}
+	header := appendArrayToken(nil, 3)
+	header = appendBulkString(header, "SET")
+	header = appendBulkString(header, c.keyPrefix+key)
+	header = append(header, '$')
+	header = append(header, strconv.FormatInt(size, 10)...)
+	header = append(header, crlf...)
+	w := &setWriter{client: c, conn: conn, remaining: size}
+	if err := c.stats.write(conn, header); err != nil {
+		w.finish(err)
+		return nil, err
+	}
+	return w, nil
+}
+
+type setWriter struct {
+	client    *Client
+	conn      net.Conn
+	remaining int64
+	err       error
+	closed    bool
+}
+
+func (w *setWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fmt.Errorf("redis: write to closed SetWriter")
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	if int64(len(p)) > w.remaining {
+		w.err = fmt.Errorf("redis: SetWriter got more than the declared size")
+		return 0, w.err
+	}
+	n, err := w.conn.Write(p)
+	w.remaining -= int64(n)
+	atomic.AddInt64(&w.client.stats.written, int64(n))
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+func (w *setWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	err := w.err
+	if err == nil && w.remaining != 0 {
+		err = fmt.Errorf("redis: SetWriter closed %v bytes short of the declared size", w.remaining)
+	}
+	if err == nil {
+		err = w.client.stats.write(w.conn, crlf)
+	}
+	if err == nil {
+		err = expectOK(bufio.NewReader(w.client.stats.reader(w.conn)))
+	}
+	w.finish(err)
+	return err
+}
+
+// finish hands the connection back, the lifecycle.end counterpart to SetWriter's begin.
+func (w *setWriter) finish(err error) {
+	w.closed = true
+	w.client.putConn(w.conn, err)
+	w.client.lifecycle.end()
+}
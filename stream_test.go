@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestClient_SetWriter(t *testing.T) {
+	t.Parallel()
+	t.Run("Streams the value as a SET", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := command("SET", "Foo", "barbaz")
+		conn, serv := net.Pipe()
+		written := make(chan string, 1)
+		go func() {
+			defer serv.Close()
+			// the command arrives over several writes
+			buf := make([]byte, len(want))
+			if _, err := io.ReadFull(serv, buf); err != nil {
+				return
+			}
+			written <- string(buf)
+			_, _ = serv.Write(okString)
+		}()
+		client.pool <- conn
+
+		w, err := client.SetWriter(context.Background(), "Foo", 6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(w, strings.NewReader("barbaz")); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+
+		if got := <-written; got != string(want) {
+			t.Errorf("SetWriter() wrote %q, want %q", got, want)
+		}
+		if len(client.pool) != 1 {
+			t.Errorf("The connection should have been returned to the pool")
+		}
+	})
+	t.Run("Writing more than size fails", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, okString)
+
+		w, err := client.SetWriter(context.Background(), "Foo", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte("bar")); err == nil {
+			t.Errorf("Write() should have failed")
+		}
+		if err := w.Close(); err == nil {
+			t.Errorf("Close() should have failed")
+		}
+		if len(client.pool) != 0 {
+			t.Errorf("The connection should have been discarded")
+		}
+	})
+	t.Run("Closing short of size fails", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a peer that never replies, so nothing but the writes can block
+		conn, serv := net.Pipe()
+		go func() {
+			defer serv.Close()
+			_, _ = io.Copy(io.Discard, serv)
+		}()
+		client.pool <- conn
+
+		w, err := client.SetWriter(context.Background(), "Foo", 6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte("bar")); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err == nil {
+			t.Errorf("Close() should have failed")
+		}
+		if len(client.pool) != 0 {
+			t.Errorf("The connection should have been discarded")
+		}
+	})
+}
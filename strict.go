@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// ProtocolError is returned when a reply is malformed, and in strict mode, see WithStrictReplies, when a reply isn't
+// of the type the command replies with. The connection is discarded, as the rest of the reply is left unread.
+type ProtocolError struct {
+	// Want holds the type bytes the command replies with, such as "$" for a bulk string
+	Want string
+	// Got is the type byte of the reply
+	Got byte
+	// Line is set instead of Want and Got when a line of the reply isn't terminated by CRLF, such as one cut short
+	// by a proxy
+	Line string
+}
+
+func (e ProtocolError) Error() string {
+	if e.Line != "" {
+		return fmt.Sprintf("redis: protocol error: line not terminated by CRLF: %q", e.Line)
+	}
+	return fmt.Sprintf("redis: protocol error: expected a reply of type %q but got %q", e.Want, e.Got)
+}
+
+// WithStrictReplies makes the typed methods, such as Get or Incr, check that replies have exactly the type the
+// command replies with, and fail with a ProtocolError otherwise. By default some leeway is allowed, such as a bulk
+// string in reply to SET, or a RESP3 verbatim string in place of a bulk string.
+// It is meant for tests, to catch mismatched servers or misbehaving proxies early. Do is never checked.
+func WithStrictReplies() Option {
+	return func(c *Client) {
+		c.strictReplies = true
+	}
+}
+
+// strictRead returns read, checking first in strict mode that the reply is an error or of one of types.
+func (c *Client) strictRead(types string, read func(reader *bufio.Reader) error) func(reader *bufio.Reader) error {
+	if !c.strictReplies {
+		return read
+	}
+	return func(reader *bufio.Reader) error {
+		msgType, err := reader.Peek(1)
+		if err != nil {
+			return err
+		}
+		if msgType[0] != '-' && !strings.ContainsRune(types, rune(msgType[0])) {
+			return ProtocolError{Want: types, Got: msgType[0]}
+		}
+		return read(reader)
+	}
+}
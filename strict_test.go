@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithStrictReplies(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		strict   bool
+		call     func(ctx context.Context, c *Client) error
+		response []byte
+		wantErr  bool
+	}{
+		{
+			name:     "Lenient SET accepts a bulk string",
+			call:     func(ctx context.Context, c *Client) error { return c.Set(ctx, "k", "v") },
+			response: asBulkString("OK"),
+		},
+		{
+			name:     "Strict SET wants a simple string",
+			strict:   true,
+			call:     func(ctx context.Context, c *Client) error { return c.Set(ctx, "k", "v") },
+			response: asBulkString("OK"),
+			wantErr:  true,
+		},
+		{
+			name:   "Strict GET wants a bulk string",
+			strict: true,
+			call: func(ctx context.Context, c *Client) error {
+				_, _, err := c.Get(ctx, "k")
+				return err
+			},
+			response: []byte("=7\r\ntxt:bar\r\n"),
+			wantErr:  true,
+		},
+		{
+			name:   "Strict INCR wants an integer",
+			strict: true,
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Incr(ctx, "k")
+				return err
+			},
+			response: asBulkString("1"),
+			wantErr:  true,
+		},
+		{
+			name: "Replies cut short are protocol errors, strict or not",
+			call: func(ctx context.Context, c *Client) error {
+				return c.Set(ctx, "k", "v")
+			},
+			response: []byte("+O\n"),
+			wantErr:  true,
+		},
+		{
+			name:   "Strict mode lets matching replies through",
+			strict: true,
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Incr(ctx, "k")
+				return err
+			},
+			response: asInteger(1),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var opts []Option
+			if tt.strict {
+				opts = append(opts, WithStrictReplies())
+			}
+			client, err := New(context.Background(), "-1", opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.pool <- fakeConn(t, tt.response)
+
+			err = tt.call(context.Background(), client)
+
+			var protocolErr ProtocolError
+			if errors.As(err, &protocolErr) != tt.wantErr {
+				t.Errorf("error = %v, want a ProtocolError %v", err, tt.wantErr)
+			}
+			if tt.wantErr && len(client.pool) != 0 {
+				t.Errorf("the connection should have been discarded")
+			}
+		})
+	}
+}
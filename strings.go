@@ -0,0 +1,256 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrValueMismatch is returned by SetConfirm when reading key back doesn't return the value just set.
+var ErrValueMismatch = errors.New("redis: value read back doesn't match the value set")
+
+// SetConfirm is like Set, but reads key back with GET afterwards, returning ErrValueMismatch if it no longer holds
+// value, such as when another client wrote or deleted it in between. It is meant for correctness sensitive tests.
+// With WithReadReplicas the read back goes to a replica, so replication lag causes mismatches too.
+func (c *Client) SetConfirm(ctx context.Context, key, value string) error {
+	if err := c.Set(ctx, key, value); err != nil {
+		return err
+	}
+	// straight from Redis, even WithLocalCache
+	got, exists, err := c.get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !exists || got != value {
+		return fmt.Errorf("%w: key %q", ErrValueMismatch, key)
+	}
+	return nil
+}
+
+// BulkSet sets every key to its value from pairs, pipelining the SETs batchSize at a time, which is much faster than
+// a round trip each when seeding or importing data. pairs is called once and yields the pairs one at a time, so they
+// needn't all be in memory, and it has the shape of an iter.Seq2[string, string] for callers on newer Go.
+//
+// It stops at the first batch with an error, after reading all of that batch's replies, and reports the first key
+// that failed. Keys in earlier batches, and the rest of the failing batch, may have been set.
+func (c *Client) BulkSet(ctx context.Context, pairs func(yield func(key, value string) bool), batchSize int) error {
+	if batchSize < 1 {
+		return fmt.Errorf("redis: BulkSet batchSize must be positive, got %v", batchSize)
+	}
+	cmds := make([][]string, 0, batchSize)
+	flush := func() error {
+		var failed string
+		var firstErr error
+		err := c.execPipeline(ctx, cmds, func(reader *bufio.Reader) error {
+			failed, firstErr = "", nil
+			for _, args := range cmds {
+				err := expectOK(reader)
+				if err != nil && !isRedisError(err) {
+					return err
+				}
+				if err != nil && firstErr == nil {
+					failed, firstErr = args[1], err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("redis: BulkSet of the batch starting at key %q: %w", cmds[0][1], err)
+		}
+		if firstErr != nil {
+			return fmt.Errorf("redis: BulkSet of key %q: %w", failed, firstErr)
+		}
+		cmds = cmds[:0]
+		return nil
+	}
+	var err error
+	pairs(func(key, value string) bool {
+		if err != nil {
+			return false
+		}
+		cmds = append(cmds, []string{"SET", key, value})
+		if len(cmds) == batchSize {
+			err = flush()
+		}
+		return err == nil
+	})
+	if err == nil && len(cmds) > 0 {
+		err = flush()
+	}
+	return err
+}
+
+// GetDel gets the value of key and deletes the key, like Get followed by a DEL. Requires Redis 6.2.
+func (c *Client) GetDel(ctx context.Context, key string) (value string, exists bool, err error) {
+	result, cmd := getDelCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func getDelCmd(key string) (*StringResult, pendingCmd) {
+	return newStringCmd("GETDEL", key)
+}
+
+// GetEx gets the value of key and sets its time to live to ttl, at millisecond precision.
+// A ttl of zero or less removes any existing time to live instead. Requires Redis 6.2.
+func (c *Client) GetEx(ctx context.Context, key string, ttl time.Duration) (value string, exists bool, err error) {
+	result, cmd := getExCmd(key, ttl)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func getExCmd(key string, ttl time.Duration) (*StringResult, pendingCmd) {
+	if ttl <= 0 {
+		return newStringCmd("GETEX", key, "PERSIST")
+	}
+	return newStringCmd("GETEX", key, "PX", strconv.FormatInt(ttlMillis(ttl), 10))
+}
+
+// Incr increments the integer stored at key by one and returns the new value. A missing key is treated as 0.
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	result, cmd := incrCmd(key)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func incrCmd(key string) (*IntResult, pendingCmd) {
+	return newIntCmd("INCR", key)
+}
+
+// IncrByFloat increments the number stored at key by delta and returns the new value. A missing key is treated as 0.
+func (c *Client) IncrByFloat(ctx context.Context, key string, delta float64) (float64, error) {
+	result, cmd := incrByFloatCmd(key, delta)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func incrByFloatCmd(key string, delta float64) (*FloatResult, pendingCmd) {
+	return newFloatCmd("INCRBYFLOAT", key, formatFloat(delta))
+}
+
+// formatFloat formats f with as many digits as it takes to read back exactly, so deltas don't drift.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// SetEx sets key to value with a mandatory time to live in seconds, using the dedicated SETEX command.
+func (c *Client) SetEx(ctx context.Context, key, value string, seconds int64) error {
+	result, cmd := setExCmd(key, value, seconds)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func setExCmd(key, value string, seconds int64) (*OKResult, pendingCmd) {
+	return newOKCmd("SETEX", key, strconv.FormatInt(seconds, 10), value)
+}
+
+// PSetEx is like SetEx, but the time to live is in milliseconds.
+func (c *Client) PSetEx(ctx context.Context, key, value string, millis int64) error {
+	result, cmd := pSetExCmd(key, value, millis)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func pSetExCmd(key, value string, millis int64) (*OKResult, pendingCmd) {
+	return newOKCmd("PSETEX", key, strconv.FormatInt(millis, 10), value)
+}
+
+// MGet gets the values of keys in one round trip. values and exists line up with keys, like a Get of each key.
+// With no keys, nothing is sent.
+func (c *Client) MGet(ctx context.Context, keys ...string) (values []string, exists []bool, err error) {
+	result, cmd := mgetCmd(keys)
+	c.execCmd(ctx, cmd)
+	return result.Result()
+}
+
+func mgetCmd(keys []string) (*ValuesResult, pendingCmd) {
+	r := &ValuesResult{err: ErrNotExecuted}
+	if len(keys) == 0 {
+		r.values, r.exists, r.err = []string{}, []bool{}, nil
+		return r, pendingCmd{}
+	}
+	return r, pendingCmd{
+		args:  append([]string{"MGET"}, keys...),
+		types: "*",
+		read: func(reader *bufio.Reader) error {
+			r.values, r.exists, r.err = readValuesReply(reader, len(keys))
+			return r.err
+		},
+		fail: func(err error) { r.err = err },
+	}
+}
+
+// readValuesReply reads the reply of an MGET of n keys, where a nil element is a missing key.
+func readValuesReply(reader *bufio.Reader, n int) ([]string, []bool, error) {
+	reply, err := readReply(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	elems, ok := reply.([]interface{})
+	if !ok || len(elems) != n {
+		return nil, nil, fmt.Errorf("redis: expected %v values but got: %v", n, reply)
+	}
+	values, exists := make([]string, len(elems)), make([]bool, len(elems))
+	for i, elem := range elems {
+		if elem == nil {
+			continue
+		}
+		value, ok := elem.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("redis: expected a value but got: %v", elem)
+		}
+		values[i], exists[i] = value, true
+	}
+	return values, exists, nil
+}
+
+// GetScan gets the value of key and stores it in dest, in the manner of database/sql's Scan. dest must be a *string,
+// *[]byte, *int64, *float64, *bool or an encoding.TextUnmarshaler. It returns ErrKeyNotFound if key doesn't exist,
+// leaving dest untouched, and an error if the value doesn't convert to dest's type.
+func (c *Client) GetScan(ctx context.Context, key string, dest interface{}) error {
+	value, exists, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrKeyNotFound
+	}
+	return scanValue(value, dest)
+}
+
+func scanValue(value string, dest interface{}) error {
+	var err error
+	switch d := dest.(type) {
+	case *string:
+		*d = value
+	case *[]byte:
+		*d = []byte(value)
+	case *int64:
+		var n int64
+		if n, err = strconv.ParseInt(value, 10, 64); err == nil {
+			*d = n
+		}
+	case *float64:
+		var f float64
+		if f, err = strconv.ParseFloat(value, 64); err == nil {
+			*d = f
+		}
+	case *bool:
+		var b bool
+		if b, err = strconv.ParseBool(value); err == nil {
+			*d = b
+		}
+	case encoding.TextUnmarshaler:
+		err = d.UnmarshalText([]byte(value))
+	default:
+		return fmt.Errorf("redis: unsupported GetScan destination %T", dest)
+	}
+	if err != nil {
+		return fmt.Errorf("redis: can't scan %q into %T: %w", value, dest, err)
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStringCommands(t *testing.T) {
+	t.Parallel()
+	runCommandTests(t, []commandTest{
+		{
+			name: "IncrByFloat",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.IncrByFloat(ctx, "k", 1e-7)
+			},
+			response:    asBulkString("1.0000001"),
+			wantCommand: []string{"INCRBYFLOAT", "k", "0.0000001"},
+			want:        1.0000001,
+		},
+		{
+			name: "IncrByFloat of a value that isn't a number",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.IncrByFloat(ctx, "k", 1)
+			},
+			response:    asSimpleErrorString("ERR value is not a valid float"),
+			wantCommand: []string{"INCRBYFLOAT", "k", "1"},
+			want:        float64(0),
+			wantErr:     true,
+		},
+		{
+			name: "MGet",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				values, exists, err := c.MGet(ctx, "a", "b", "c")
+				return []interface{}{values, exists}, err
+			},
+			response:    asArray(asBulkString("1"), nullString, asBulkString("")),
+			wantCommand: []string{"MGET", "a", "b", "c"},
+			want:        []interface{}{[]string{"1", "", ""}, []bool{true, false, true}},
+		},
+		{
+			name: "GetDel",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				v, exists, err := c.GetDel(ctx, "k")
+				return []interface{}{v, exists}, err
+			},
+			response:    asBulkString("v"),
+			wantCommand: []string{"GETDEL", "k"},
+			want:        []interface{}{"v", true},
+		},
+		{
+			name: "GetEx",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				v, exists, err := c.GetEx(ctx, "k", 1500*time.Millisecond)
+				return []interface{}{v, exists}, err
+			},
+			response:    nullString,
+			wantCommand: []string{"GETEX", "k", "PX", "1500"},
+			want:        []interface{}{"", false},
+		},
+		{
+			name: "GetEx under a millisecond",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				v, exists, err := c.GetEx(ctx, "k", time.Nanosecond)
+				return []interface{}{v, exists}, err
+			},
+			response:    asBulkString("v"),
+			wantCommand: []string{"GETEX", "k", "PX", "1"},
+			want:        []interface{}{"v", true},
+		},
+		{
+			name: "GetEx without a ttl persists",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				v, exists, err := c.GetEx(ctx, "k", 0)
+				return []interface{}{v, exists}, err
+			},
+			response:    asBulkString("v"),
+			wantCommand: []string{"GETEX", "k", "PERSIST"},
+			want:        []interface{}{"v", true},
+		},
+		{
+			name: "Incr",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Incr(ctx, "k")
+			},
+			response:    asInteger(3),
+			wantCommand: []string{"INCR", "k"},
+			want:        int64(3),
+		},
+		{
+			name: "Incr errors",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.Incr(ctx, "k")
+			},
+			response:    asSimpleErrorString("ERR value is not an integer or out of range"),
+			wantCommand: []string{"INCR", "k"},
+			want:        int64(0),
+			wantErr:     true,
+		},
+		{
+			name: "SetEx",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.SetEx(ctx, "k", "v", 10)
+			},
+			response:    okString,
+			wantCommand: []string{"SETEX", "k", "10", "v"},
+		},
+		{
+			name: "PSetEx",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.PSetEx(ctx, "k", "v", 1500)
+			},
+			response:    okString,
+			wantCommand: []string{"PSETEX", "k", "1500", "v"},
+		},
+		{
+			name: "SetEx errors on an invalid ttl",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.SetEx(ctx, "k", "v", 0)
+			},
+			response:    asSimpleErrorString("ERR invalid expire time in 'setex' command"),
+			wantCommand: []string{"SETEX", "k", "0", "v"},
+			wantErr:     true,
+		},
+	})
+}
+
+func TestClient_SetConfirm(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		readBack []byte
+		wantErr  error
+	}{
+		{"Matching read back", asBulkString("v"), nil},
+		{"Changed in between", asBulkString("other"), ErrValueMismatch},
+		{"Deleted in between", nullString, ErrValueMismatch},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := New(context.Background(), "-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn, requests := recordingConn(t, okString, tt.readBack)
+			client.pool <- conn
+
+			err = client.SetConfirm(context.Background(), "k", "v")
+
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("SetConfirm() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			want := string(command("SET", "k", "v")) + string(command("GET", "k"))
+			if got := <-requests + <-requests; got != want {
+				t.Errorf("SetConfirm() sent %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestClient_GetScan(t *testing.T) {
+	t.Parallel()
+	scan := func(dest interface{}) func(ctx context.Context, c *Client) (interface{}, error) {
+		return func(ctx context.Context, c *Client) (interface{}, error) {
+			err := c.GetScan(ctx, "k", dest)
+			return reflect.ValueOf(dest).Elem().Interface(), err
+		}
+	}
+	var s string
+	var b []byte
+	var n int64
+	var f float64
+	var ok bool
+	var when time.Time
+	var unsupported int
+	var mismatch int64
+	runCommandTests(t, []commandTest{
+		{name: "string", call: scan(&s), response: asBulkString("v"), wantCommand: []string{"GET", "k"}, want: "v"},
+		{name: "[]byte", call: scan(&b), response: asBulkString("v"), wantCommand: []string{"GET", "k"}, want: []byte("v")},
+		{name: "int64", call: scan(&n), response: asBulkString("-42"), wantCommand: []string{"GET", "k"}, want: int64(-42)},
+		{name: "float64", call: scan(&f), response: asBulkString("1.5"), wantCommand: []string{"GET", "k"}, want: 1.5},
+		{name: "bool", call: scan(&ok), response: asBulkString("1"), wantCommand: []string{"GET", "k"}, want: true},
+		{
+			name:        "TextUnmarshaler",
+			call:        scan(&when),
+			response:    asBulkString("2024-01-02T03:04:05Z"),
+			wantCommand: []string{"GET", "k"},
+			want:        time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name:        "Type mismatch",
+			call:        scan(&mismatch),
+			response:    asBulkString("abc"),
+			wantCommand: []string{"GET", "k"},
+			want:        int64(0),
+			wantErr:     true,
+		},
+		{
+			name:        "Unsupported destination",
+			call:        scan(&unsupported),
+			response:    asBulkString("1"),
+			wantCommand: []string{"GET", "k"},
+			want:        0,
+			wantErr:     true,
+		},
+	})
+	t.Run("Missing key", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.pool <- fakeConn(t, nullString)
+		dest := "untouched"
+
+		err = client.GetScan(context.Background(), "k", &dest)
+
+		if !errors.Is(err, ErrKeyNotFound) || dest != "untouched" {
+			t.Errorf("GetScan() error = %v, dest = %q, want %v and dest untouched", err, dest, ErrKeyNotFound)
+		}
+	})
+}
+
+func TestClient_BulkSet(t *testing.T) {
+	t.Parallel()
+	// pairsOf yields key0=value0, key1=value1 and so on, counting how many pairs were taken
+	pairsOf := func(n int, taken *int) func(yield func(key, value string) bool) {
+		return func(yield func(key, value string) bool) {
+			for i := 0; i < n; i++ {
+				*taken++
+				if !yield("key"+strconv.Itoa(i), "value"+strconv.Itoa(i)) {
+					return
+				}
+			}
+		}
+	}
+	oks := func(n int) []byte {
+		var b []byte
+		for i := 0; i < n; i++ {
+			b = append(b, okString...)
+		}
+		return b
+	}
+	t.Run("Sets every pair in batches", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, requests := recordingConn(t, oks(2), oks(2), oks(1))
+		client.pool <- conn
+		var taken int
+
+		err = client.BulkSet(context.Background(), pairsOf(5, &taken), 2)
+
+		if err != nil {
+			t.Fatalf("BulkSet() error = %v", err)
+		}
+		for _, want := range []string{
+			string(command("SET", "key0", "value0")) + string(command("SET", "key1", "value1")),
+			string(command("SET", "key2", "value2")) + string(command("SET", "key3", "value3")),
+			string(command("SET", "key4", "value4")),
+		} {
+			if got := <-requests; got != want {
+				t.Errorf("BulkSet() sent %q, want %q", got, want)
+			}
+		}
+	})
+	t.Run("Stops at the first batch with an error", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		failing := append(asSimpleErrorString("OOM command not allowed when used memory > 'maxmemory'."), okString...)
+		client.pool <- fakeConn(t, oks(2), failing)
+		var taken int
+
+		err = client.BulkSet(context.Background(), pairsOf(10, &taken), 2)
+
+		var redisErr Error
+		if !errors.As(err, &redisErr) || !strings.Contains(err.Error(), `"key2"`) {
+			t.Errorf("BulkSet() error = %v, want the Error for key2", err)
+		}
+		if taken != 4 {
+			t.Errorf("BulkSet() took %v pairs, want %v", taken, 4)
+		}
+	})
+	t.Run("Rejects a batch size below 1", func(t *testing.T) {
+		t.Parallel()
+		client, err := New(context.Background(), "-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var taken int
+		if err := client.BulkSet(context.Background(), pairsOf(1, &taken), 0); err == nil {
+			t.Errorf("BulkSet() should have failed")
+		}
+	})
+}
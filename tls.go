@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+)
+
+// WithTLS connects to Redis over TLS, configured by config. An empty config.ServerName is filled in with the host
+// dialed, to check the server's certificate against, as usual. A ServerName that is set is kept as it is, both as the
+// SNI name and for the certificate, so it may differ from the host dialed, such as behind a multi-tenant proxy.
+// config is cloned, so changing it later has no effect on the Client, and a nil config uses the defaults.
+func WithTLS(config *tls.Config) Option {
+	return func(c *Client) {
+		if config == nil {
+			config = &tls.Config{}
+		}
+		c.tlsConfig = config.Clone()
+	}
+}
+
+// handshake runs a TLS handshake over conn, closing conn if it fails. address is the one dialed, whose host stands in
+// for an empty config.ServerName.
+func handshake(ctx context.Context, conn net.Conn, config *tls.Config, address string) (net.Conn, error) {
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
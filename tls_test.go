@@ -0,0 +1,133 @@
+package redis
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// selfSigned returns a certificate for names, and a pool trusting it.
+func selfSigned(t *testing.T, names ...string) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     names,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+// listenTLS answers every command with +PONG over TLS, sending the SNI name of each handshake on the returned channel.
+func listenTLS(t *testing.T, cert tls.Certificate) (port string, serverNames <-chan string) {
+	t.Helper()
+	names := make(chan string, 10)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			names <- hello.ServerName
+			return nil, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 4096)
+				for {
+					if _, err := conn.Read(buf); err != nil {
+						return
+					}
+					if _, err := conn.Write(asSimpleString("PONG")); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	_, port, _ = net.SplitHostPort(l.Addr().String())
+	return port, names
+}
+
+func TestWithTLS(t *testing.T) {
+	t.Parallel()
+	cert, pool := selfSigned(t, "tenant.example", "localhost")
+	port, serverNames := listenTLS(t, cert)
+	tests := []struct {
+		name       string
+		serverName string
+		want       string
+	}{
+		{"ServerName defaults to the host dialed", "", "localhost"},
+		{"A set ServerName is kept", "tenant.example", "tenant.example"},
+	}
+	// not parallel, so the SNI names arrive in the order of the tests
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			config := &tls.Config{RootCAs: pool, ServerName: tt.serverName}
+			client, err := New(context.Background(), net.JoinHostPort("localhost", port), WithTLS(config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			got, err := client.Do(context.Background(), "PING")
+
+			if got != "PONG" || err != nil {
+				t.Errorf("Do() got = %v, %v", got, err)
+			}
+			if got := <-serverNames; got != tt.want {
+				t.Errorf("handshake used SNI %q, want %q", got, tt.want)
+			}
+			if config.ServerName != tt.serverName {
+				t.Errorf("the caller's config was changed")
+			}
+		})
+	}
+	t.Run("A certificate for another name fails the handshake", func(t *testing.T) {
+		t.Parallel()
+		config := &tls.Config{RootCAs: pool, ServerName: "other.example"}
+		client, err := New(context.Background(), net.JoinHostPort("localhost", port), WithTLS(config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Do(context.Background(), "PING"); err == nil {
+			t.Errorf("Do() should have failed to verify the certificate")
+		}
+		if len(client.sem) != 0 {
+			t.Errorf("the failed connection should have freed its slot, %v in use", len(client.sem))
+		}
+	})
+}
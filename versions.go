@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedCommand is returned for commands the connected server is too old to run.
+var ErrUnsupportedCommand = errors.New("redis: command not supported by server")
+
+// minVersions are the Redis versions that commands, or subcommands such as "CLIENT NO-EVICT", were added in.
+var minVersions = map[string]string{
+	"GETDEL":          "6.2.0",
+	"GETEX":           "6.2.0",
+	"EXPIRETIME":      "7.0.0",
+	"PEXPIRETIME":     "7.0.0",
+	"CLIENT NO-EVICT": "7.0.0",
+	"CLIENT NO-TOUCH": "7.2.0",
+	"COMMAND LIST":    "7.0.0",
+	"ZMPOP":           "7.0.0",
+	"SINTERCARD":      "7.0.0",
+	"ZINTERCARD":      "7.0.0",
+	"BZMPOP":          "7.0.0",
+}
+
+// checkSupported returns ErrUnsupportedCommand if any of cmds needs a newer server than the one ServerInfo found.
+// Until ServerInfo has been called successfully the version isn't known, and every command is sent as is.
+func (c *Client) checkSupported(cmds [][]string) error {
+	info := c.serverInfo.get()
+	if info == nil {
+		return nil
+	}
+	for _, args := range cmds {
+		name, min := minVersion(args)
+		if min != "" && compareVersions(info.Version, min) < 0 {
+			return fmt.Errorf("%w: %v requires Redis %v, server is %v", ErrUnsupportedCommand, name, min, info.Version)
+		}
+	}
+	return nil
+}
+
+func minVersion(args []string) (name, version string) {
+	if len(args) == 0 {
+		return "", ""
+	}
+	name = strings.ToUpper(args[0])
+	if len(args) > 1 {
+		sub := name + " " + strings.ToUpper(args[1])
+		if v, ok := minVersions[sub]; ok {
+			return sub, v
+		}
+	}
+	return name, minVersions[name]
+}
+
+// compareVersions compares dotted versions numerically, returning -1, 0 or 1. Missing parts count as 0.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUnsupportedCommands(t *testing.T) {
+	t.Parallel()
+	client, err := New(context.Background(), "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.serverInfo.info = &ServerInfo{Version: "6.0.16"}
+	conn, written := recordingConn(t, asBulkString("bar"))
+	client.pool <- conn
+
+	if _, _, err := client.GetDel(context.Background(), "Foo"); !errors.Is(err, ErrUnsupportedCommand) {
+		t.Errorf("GetDel() error = %v, want %v", err, ErrUnsupportedCommand)
+	}
+	if err := client.ClientNoEvict(context.Background(), true); !errors.Is(err, ErrUnsupportedCommand) {
+		t.Errorf("ClientNoEvict() error = %v, want %v", err, ErrUnsupportedCommand)
+	}
+
+	if _, _, err := client.Get(context.Background(), "Foo"); err != nil {
+		t.Errorf("Get() error = %v", err)
+	}
+	if got := <-written; got != string(command("GET", "Foo")) {
+		t.Errorf("Only the supported command should be written, got %q", got)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"6.2.0", "6.2.0", 0},
+		{"6.2", "6.2.0", 0},
+		{"6.0.16", "6.2.0", -1},
+		{"7.0.0", "6.2.14", 1},
+		{"10.0.0", "9.9.9", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}